@@ -63,7 +63,10 @@ var (
 	}
 )
 
-const saveFilePath = "data/savegame.json"
+const (
+	saveFilePath = "data/savegame.json"
+	apiKeysPath  = "data/apikeys.json"
+)
 
 func main() {
 	engine := game.NewEngine(aircraftCosts, aircraftLeadTicks)
@@ -90,7 +93,15 @@ func main() {
 	}
 	engine.RecalcUtilization()
 
-	handler := api.New(engine)
+	authCfg, err := api.LoadAuthConfig(apiKeysPath)
+	if err != nil {
+		log.Fatalf("failed to load api keys: %v", err)
+	}
+	if authCfg == nil {
+		log.Printf("no %s found, auth disabled (dev mode)", apiKeysPath)
+	}
+
+	handler := api.New(engine, authCfg)
 
 	port := getPort()
 	log.Printf("Server listening on port %s", port)
@@ -0,0 +1,283 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Scope gates one class of mutation or read on the engine. ScopeAdmin
+// implicitly satisfies every other scope.
+type Scope string
+
+const (
+	ScopeRead        Scope = "read"
+	ScopeSimControl  Scope = "sim:control"
+	ScopeFleetWrite  Scope = "fleet:write"
+	ScopeRoutesWrite Scope = "routes:write"
+	ScopeAdmin       Scope = "admin"
+)
+
+// startingCash is the balance a newly minted owner's ledger opens with,
+// matching the single-tenant seed in cmd/server/main.go.
+const startingCash = 500_000_000
+
+// APIKey is one tenant's credential: who it belongs to, what it may do,
+// and how fast it may do it.
+type APIKey struct {
+	Key             string  `json:"key"`
+	Owner           string  `json:"owner"`
+	Scopes          []Scope `json:"scopes"`
+	RateLimitPerSec float64 `json:"rate_limit_per_sec,omitempty"`
+	RateLimitBurst  int     `json:"rate_limit_burst,omitempty"`
+}
+
+func (k APIKey) hasScope(want Scope) bool {
+	for _, s := range k.Scopes {
+		if s == want || s == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	defaultRateLimitPerSec = 5.0
+	defaultRateLimitBurst  = 20
+)
+
+// AuthConfig is the set of API keys a server instance accepts. A nil
+// *AuthConfig disables auth entirely: every request runs as the shared ""
+// owner with every scope, which is how the dev server and existing tests
+// boot without a keys file.
+type AuthConfig struct {
+	mu      sync.Mutex
+	keys    map[string]*APIKey
+	buckets map[string]*tokenBucket
+}
+
+// NewAuthConfig returns an empty, auth-enabled config — every request is
+// rejected until a key is loaded or minted.
+func NewAuthConfig() *AuthConfig {
+	return &AuthConfig{
+		keys:    make(map[string]*APIKey),
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// LoadAuthConfig reads a JSON array of APIKey from path. A missing file
+// returns (nil, nil) so callers can treat "no keys file" as dev mode
+// (auth disabled) rather than an error.
+func LoadAuthConfig(path string) (*AuthConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var keys []APIKey
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, err
+	}
+	cfg := NewAuthConfig()
+	for _, k := range keys {
+		key := k
+		cfg.keys[key.Key] = &key
+	}
+	return cfg, nil
+}
+
+// Save persists the config's keys to path as a JSON array, overwriting
+// whatever is there.
+func (a *AuthConfig) Save(path string) error {
+	a.mu.Lock()
+	keys := make([]APIKey, 0, len(a.keys))
+	for _, k := range a.keys {
+		keys = append(keys, *k)
+	}
+	a.mu.Unlock()
+	data, err := json.MarshalIndent(keys, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Mint generates a fresh key for owner with the given scopes and registers
+// it, ready to authenticate requests immediately.
+func (a *AuthConfig) Mint(owner string, scopes []Scope) (APIKey, error) {
+	raw := make([]byte, 24)
+	if _, err := rand.Read(raw); err != nil {
+		return APIKey{}, err
+	}
+	key := APIKey{
+		Key:             "ab_" + hex.EncodeToString(raw),
+		Owner:           owner,
+		Scopes:          scopes,
+		RateLimitPerSec: defaultRateLimitPerSec,
+		RateLimitBurst:  defaultRateLimitBurst,
+	}
+	a.mu.Lock()
+	a.keys[key.Key] = &key
+	a.mu.Unlock()
+	return key, nil
+}
+
+func (a *AuthConfig) lookup(raw string) (*APIKey, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	k, ok := a.keys[raw]
+	return k, ok
+}
+
+// allow consumes one token from key's bucket, creating the bucket on first
+// use from the key's own rate limit (or the package defaults).
+func (a *AuthConfig) allow(key *APIKey) bool {
+	a.mu.Lock()
+	b, ok := a.buckets[key.Key]
+	if !ok {
+		rate := key.RateLimitPerSec
+		if rate <= 0 {
+			rate = defaultRateLimitPerSec
+		}
+		burst := key.RateLimitBurst
+		if burst <= 0 {
+			burst = defaultRateLimitBurst
+		}
+		b = newTokenBucket(rate, float64(burst))
+		a.buckets[key.Key] = b
+	}
+	a.mu.Unlock()
+	return b.take()
+}
+
+// tokenBucket is a minimal token-bucket rate limiter: tokens refill
+// continuously at rate per second up to capacity, and each request
+// consumes one.
+type tokenBucket struct {
+	mu       sync.Mutex
+	rate     float64
+	capacity float64
+	tokens   float64
+	last     time.Time
+}
+
+func newTokenBucket(rate, capacity float64) *tokenBucket {
+	return &tokenBucket{rate: rate, capacity: capacity, tokens: capacity, last: time.Now()}
+}
+
+func (b *tokenBucket) take() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	elapsed := now.Sub(b.last).Seconds()
+	b.last = now
+	b.tokens += elapsed * b.rate
+	if b.tokens > b.capacity {
+		b.tokens = b.capacity
+	}
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+type authCtxKey int
+
+const (
+	ctxOwnerKey authCtxKey = iota
+	ctxScopesKey
+)
+
+func ownerFromContext(ctx context.Context) string {
+	owner, _ := ctx.Value(ctxOwnerKey).(string)
+	return owner
+}
+
+// requireScope wraps next so that, when cfg is non-nil, a request must
+// carry a valid API key (Authorization: Bearer … or X-API-Key) with the
+// given scope before next runs, and is subject to that key's rate limit.
+// A nil cfg is dev mode: next always runs as the shared "" owner with
+// every scope, preserving pre-auth behavior.
+func requireScope(cfg *AuthConfig, scope Scope) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if cfg == nil {
+				ctx := context.WithValue(r.Context(), ctxOwnerKey, "")
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+			raw := bearerToken(r)
+			if raw == "" {
+				writeJSONError(w, http.StatusUnauthorized, "missing API key")
+				return
+			}
+			key, ok := cfg.lookup(raw)
+			if !ok {
+				writeJSONError(w, http.StatusUnauthorized, "invalid API key")
+				return
+			}
+			if scope != "" && !key.hasScope(scope) {
+				writeJSONError(w, http.StatusForbidden, fmt.Sprintf("key lacks required scope %q", scope))
+				return
+			}
+			if !cfg.allow(key) {
+				writeJSONError(w, http.StatusTooManyRequests, "rate limit exceeded")
+				return
+			}
+			ctx := context.WithValue(r.Context(), ctxOwnerKey, key.Owner)
+			ctx = context.WithValue(ctx, ctxScopesKey, key.Scopes)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// bearerToken extracts the API key from Authorization: Bearer … , falling
+// back to the X-API-Key header.
+func bearerToken(r *http.Request) string {
+	if h := r.Header.Get("Authorization"); h != "" {
+		if rest, ok := strings.CutPrefix(h, "Bearer "); ok {
+			return strings.TrimSpace(rest)
+		}
+	}
+	return strings.TrimSpace(r.Header.Get("X-API-Key"))
+}
+
+// MintKeyRequest is the body for POST /admin/keys.
+type MintKeyRequest struct {
+	Owner  string  `json:"owner"`
+	Scopes []Scope `json:"scopes"`
+}
+
+func (s *Server) handleMintKey(w http.ResponseWriter, r *http.Request) {
+	if s.auth == nil {
+		writeJSONError(w, http.StatusNotFound, "auth is disabled")
+		return
+	}
+	var req MintKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "bad request")
+		return
+	}
+	if strings.TrimSpace(req.Owner) == "" || len(req.Scopes) == 0 {
+		writeJSONError(w, http.StatusBadRequest, "owner and scopes are required")
+		return
+	}
+	key, err := s.auth.Mint(req.Owner, req.Scopes)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.engine.EnsureOwner(req.Owner, startingCash)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(key)
+}
@@ -6,40 +6,64 @@ import (
 	"net/http"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
 
+	"airline_builder/internal/flightlog"
 	"airline_builder/internal/game"
 	"airline_builder/internal/models"
 
 	"github.com/go-chi/chi/v5"
 )
 
+// The HTTP surface New wires up below is described formally in
+// openapi.yaml. The directive below names oapi-codegen and an
+// oapi-codegen.yaml config, neither of which is in the tree, so it does not
+// run and there is no internal/api/gen package; handler signatures and
+// validation come from the hand-rolled json.Decode blocks here.
+//go:generate go run github.com/deepmap/oapi-codegen/cmd/oapi-codegen --config oapi-codegen.yaml openapi.yaml
+
 type Server struct {
 	engine *game.Engine
+	auth   *AuthConfig
 }
 
-// New constructs the HTTP router wired to the game engine.
-func New(engine *game.Engine) http.Handler {
-	s := &Server{engine: engine}
+// New constructs the HTTP router wired to the game engine. auth may be nil,
+// which disables authentication entirely (dev mode): every request runs as
+// the shared "" owner with every scope, matching the server's pre-auth
+// behavior so existing single-tenant callers and tests keep working.
+func New(engine *game.Engine, auth *AuthConfig) http.Handler {
+	s := &Server{engine: engine, auth: auth}
 	r := chi.NewRouter()
 	r.Use(corsMiddleware)
 
+	scoped := func(scope Scope) func(http.Handler) http.Handler {
+		return requireScope(s.auth, scope)
+	}
+
 	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("ok"))
 	})
 
-	r.Get("/airports", s.handleAirports)
-	r.Get("/aircraft/templates", s.handleAircraftTemplates)
-	r.Get("/state", s.handleState)
-	r.Post("/routes", s.handleCreateRoute)
-	r.Post("/tick", s.handleTick)
-	r.Post("/sim/start", s.handleSimStart)
-	r.Post("/sim/pause", s.handleSimPause)
-	r.Post("/sim/speed", s.handleSimSpeed)
-	r.Post("/fleet/purchase", s.handlePurchase)
-	r.Post("/fleet/maintenance", s.handleMaintenance)
-	r.Post("/analysis/route", s.handleRouteAnalysis)
+	r.With(scoped(ScopeRead)).Get("/airports", s.handleAirports)
+	r.With(scoped(ScopeRead)).Get("/aircraft/templates", s.handleAircraftTemplates)
+	r.With(scoped(ScopeRead)).Get("/state", s.handleState)
+	r.With(scoped(ScopeRoutesWrite)).Post("/routes", s.handleCreateRoute)
+	r.With(scoped(ScopeSimControl)).Post("/tick", s.handleTick)
+	r.With(scoped(ScopeSimControl)).Post("/sim/start", s.handleSimStart)
+	r.With(scoped(ScopeSimControl)).Post("/sim/pause", s.handleSimPause)
+	r.With(scoped(ScopeSimControl)).Post("/sim/speed", s.handleSimSpeed)
+	r.With(scoped(ScopeFleetWrite)).Post("/fleet/purchase", s.handlePurchase)
+	r.With(scoped(ScopeFleetWrite)).Post("/fleet/maintenance", s.handleMaintenance)
+	r.With(scoped(ScopeRead)).Post("/analysis/route", s.handleRouteAnalysis)
+	r.With(scoped(ScopeRead)).Post("/analysis/quote", s.handleAnalysisQuote)
+	r.With(scoped(ScopeRoutesWrite)).Post("/bookings", s.handleCreateBooking)
+	r.With(scoped(ScopeRoutesWrite)).Patch("/bookings/{id}/status", s.handleUpdateBookingStatus)
+	r.With(scoped(ScopeRead)).Get("/bookings", s.handleListBookings)
+	r.With(scoped(ScopeRead)).Get("/flights", s.handleListFlights)
+	r.With(scoped(ScopeRead)).Get("/flights/aggregate", s.handleFlightsAggregate)
+	r.With(scoped(ScopeAdmin)).Post("/admin/keys", s.handleMintKey)
 
 	return r
 }
@@ -71,25 +95,29 @@ func (s *Server) handleAircraftTemplates(w http.ResponseWriter, r *http.Request)
 
 func (s *Server) handleState(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(s.engine.State())
+	_ = json.NewEncoder(w).Encode(s.engine.State(ownerFromContext(r.Context())))
+}
+
+// CreateRouteRequest is the body for POST /routes.
+type CreateRouteRequest struct {
+	From       string  `json:"from"`
+	To         string  `json:"to"`
+	Via        string  `json:"via,omitempty"`
+	AircraftID string  `json:"aircraft_id"`
+	Frequency  int     `json:"frequency_per_day"`
+	OneWay     bool    `json:"one_way"`
+	UserPrice  float64 `json:"user_price"`
 }
 
 func (s *Server) handleCreateRoute(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		From       string  `json:"from"`
-		To         string  `json:"to"`
-		Via        string  `json:"via,omitempty"`
-		AircraftID string  `json:"aircraft_id"`
-		Frequency  int     `json:"frequency_per_day"`
-		OneWay     bool    `json:"one_way"`
-		UserPrice  float64 `json:"user_price"`
-	}
+	var req CreateRouteRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeJSONError(w, http.StatusBadRequest, "bad request")
 		return
 	}
 
-	route, err := s.engine.BuildRoute(req.From, req.To, req.Via, req.AircraftID, req.Frequency, req.UserPrice)
+	owner := ownerFromContext(r.Context())
+	route, err := s.engine.BuildRoute(owner, req.From, req.To, req.Via, req.AircraftID, req.Frequency, req.UserPrice)
 	if err != nil {
 		msg := err.Error()
 		if err == http.ErrBodyNotAllowed {
@@ -98,7 +126,7 @@ func (s *Server) handleCreateRoute(w http.ResponseWriter, r *http.Request) {
 		writeJSONError(w, http.StatusBadRequest, msg)
 		return
 	}
-	if !req.OneWay && s.engine.MarketExists(route.From, route.To) {
+	if !req.OneWay && s.engine.MarketExists(owner, route.From, route.To) {
 		writeJSONError(w, http.StatusBadRequest, "market already served in either direction")
 		return
 	}
@@ -115,48 +143,57 @@ func (s *Server) handleCreateRoute(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleTick(w http.ResponseWriter, r *http.Request) {
 	s.engine.AdvanceTick()
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(s.engine.State())
+	_ = json.NewEncoder(w).Encode(s.engine.State(ownerFromContext(r.Context())))
+}
+
+// SimStartRequest is the body for POST /sim/start.
+type SimStartRequest struct {
+	Speed int `json:"speed"`
 }
 
 func (s *Server) handleSimStart(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		Speed int `json:"speed"`
-	}
+	var req SimStartRequest
 	_ = json.NewDecoder(r.Body).Decode(&req)
 	s.engine.StartSim(req.Speed)
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(s.engine.State())
+	_ = json.NewEncoder(w).Encode(s.engine.State(ownerFromContext(r.Context())))
 }
 
 func (s *Server) handleSimPause(w http.ResponseWriter, r *http.Request) {
 	s.engine.PauseSim()
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(s.engine.State())
+	_ = json.NewEncoder(w).Encode(s.engine.State(ownerFromContext(r.Context())))
+}
+
+// SimSpeedRequest is the body for POST /sim/speed.
+type SimSpeedRequest struct {
+	Speed int `json:"speed"`
 }
 
 func (s *Server) handleSimSpeed(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		Speed int `json:"speed"`
-	}
+	var req SimSpeedRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Speed <= 0 {
 		writeJSONError(w, http.StatusBadRequest, "bad request")
 		return
 	}
 	s.engine.SetSpeed(req.Speed)
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(s.engine.State())
+	_ = json.NewEncoder(w).Encode(s.engine.State(ownerFromContext(r.Context())))
+}
+
+// PurchaseRequest is the body for POST /fleet/purchase.
+type PurchaseRequest struct {
+	TemplateID string `json:"template_id"`
+	Mode       string `json:"mode"`
 }
 
 func (s *Server) handlePurchase(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		TemplateID string `json:"template_id"`
-		Mode       string `json:"mode"`
-	}
+	var req PurchaseRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.TemplateID == "" {
 		writeJSONError(w, http.StatusBadRequest, "bad request")
 		return
 	}
-	craft, err := s.engine.PurchaseAircraft(req.TemplateID, req.Mode)
+	craft, err := s.engine.PurchaseAircraft(ownerFromContext(r.Context()), req.TemplateID, req.Mode)
 	if err != nil {
 		writeJSONError(w, http.StatusBadRequest, err.Error())
 		return
@@ -165,15 +202,18 @@ func (s *Server) handlePurchase(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(craft)
 }
 
+// MaintenanceRequest is the body for POST /fleet/maintenance.
+type MaintenanceRequest struct {
+	OwnedID string `json:"owned_id"`
+}
+
 func (s *Server) handleMaintenance(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		OwnedID string `json:"owned_id"`
-	}
+	var req MaintenanceRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.OwnedID == "" {
 		writeJSONError(w, http.StatusBadRequest, "bad request")
 		return
 	}
-	craft, err := s.engine.Maintain(req.OwnedID, 3)
+	craft, err := s.engine.Maintain(ownerFromContext(r.Context()), req.OwnedID, 3)
 	if err != nil {
 		writeJSONError(w, http.StatusBadRequest, err.Error())
 		return
@@ -182,6 +222,98 @@ func (s *Server) handleMaintenance(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(craft)
 }
 
+// CreateBookingRequest is the body for POST /bookings.
+type CreateBookingRequest struct {
+	PassengerID string  `json:"passenger_id"`
+	RouteID     string  `json:"route_id"`
+	PricePaid   float64 `json:"price_paid"`
+}
+
+func (s *Server) handleCreateBooking(w http.ResponseWriter, r *http.Request) {
+	var req CreateBookingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RouteID == "" {
+		writeJSONError(w, http.StatusBadRequest, "bad request")
+		return
+	}
+	booking, err := s.engine.CreateBooking(req.PassengerID, req.RouteID, req.PricePaid)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(booking)
+}
+
+// UpdateBookingStatusRequest is the body for PATCH /bookings/{id}/status.
+type UpdateBookingStatusRequest struct {
+	Status models.BookingStatus `json:"status"`
+}
+
+func (s *Server) handleUpdateBookingStatus(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	var req UpdateBookingStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Status == "" {
+		writeJSONError(w, http.StatusBadRequest, "bad request")
+		return
+	}
+	booking, err := s.engine.UpdateBookingStatus(id, req.Status)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(booking)
+}
+
+func (s *Server) handleListBookings(w http.ResponseWriter, r *http.Request) {
+	route := r.URL.Query().Get("route")
+	status := models.BookingStatus(r.URL.Query().Get("status"))
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(s.engine.ListBookings(route, status))
+}
+
+// FlightsResponse is the body for GET /flights: a page of records plus the
+// total match count so the frontend can paginate.
+type FlightsResponse struct {
+	Flights []flightlog.FlightRecord `json:"flights"`
+	Total   int                      `json:"total"`
+}
+
+func (s *Server) handleListFlights(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	query := flightlog.Query{
+		From:     q.Get("from"),
+		To:       q.Get("to"),
+		Tag:      q.Get("tag"),
+		Aircraft: q.Get("aircraft"),
+		Limit:    atoiDefault(q.Get("limit"), 0),
+		Offset:   atoiDefault(q.Get("offset"), 0),
+	}
+	if v := q.Get("since_tick"); v != "" {
+		query.SinceTick = atoiDefault(v, 0)
+		query.HasSince = true
+	}
+	if v := q.Get("until_tick"); v != "" {
+		query.UntilTick = atoiDefault(v, 0)
+		query.HasUntil = true
+	}
+
+	records, total := s.engine.Flights(query)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(FlightsResponse{Flights: records, Total: total})
+}
+
+func (s *Server) handleFlightsAggregate(w http.ResponseWriter, r *http.Request) {
+	by := flightlog.BucketBy(r.URL.Query().Get("by"))
+	switch by {
+	case flightlog.BucketDay, flightlog.BucketWeek, flightlog.BucketRoute:
+	default:
+		by = flightlog.BucketDay
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(s.engine.FlightsAggregate(by))
+}
+
 type RouteAnalysisRequest struct {
 	Origin        string   `json:"origin"`
 	Dest          string   `json:"dest"`
@@ -197,6 +329,10 @@ type RouteAnalysisResult struct {
 	RoiScore     float64 `json:"roi_score"`
 	Valid        bool    `json:"valid"`
 	Error        string  `json:"error,omitempty"`
+	// Schedule holds the local departure/arrival clocks the engine assigned
+	// the outbound and return legs, flagging any that can't avoid a local
+	// curfew window (see Engine.LocalSchedule).
+	Schedule []models.LegSchedule `json:"schedule,omitempty"`
 }
 
 func (s *Server) handleRouteAnalysis(w http.ResponseWriter, r *http.Request) {
@@ -325,6 +461,11 @@ func (s *Server) handleRouteAnalysis(w http.ResponseWriter, r *http.Request) {
 			roi = (dailyProfit * 365) / costToBuy * 100
 		}
 
+		schedule := s.engine.LocalSchedule([]models.RouteLegPlan{
+			{Origin: fromAp.Ident, Dest: toAp.Ident, BlockMinutes: oneWayBlock},
+			{Origin: toAp.Ident, Dest: fromAp.Ident, BlockMinutes: oneWayBlock},
+		}, int(freq))
+
 		results = append(results, RouteAnalysisResult{
 			AircraftType: ac.ID,
 			Frequency:    freq,
@@ -332,6 +473,7 @@ func (s *Server) handleRouteAnalysis(w http.ResponseWriter, r *http.Request) {
 			DailyProfit:  dailyProfit,
 			RoiScore:     roi,
 			Valid:        true,
+			Schedule:     schedule,
 		})
 	}
 
@@ -349,8 +491,151 @@ func (s *Server) handleRouteAnalysis(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(results)
 }
 
+// cabinPriceMultiplier scales the suggested fare by requested cabin; an
+// unrecognized or empty cabin defaults to economy pricing.
+var cabinPriceMultiplier = map[string]float64{
+	"economy": 1.0,
+	"premium": 1.6,
+}
+
+// QuoteRequest is the body for POST /analysis/quote.
+type QuoteRequest struct {
+	Origin string `json:"origin"`
+	Dest   string `json:"dest"`
+	Via    string `json:"via,omitempty"`
+	Pax    int    `json:"pax"`
+	Cabin  string `json:"cabin,omitempty"`
+}
+
+// QuoteResult is one currently-owned aircraft's charter estimate: can it
+// fly this leg, for roughly how much, and how soon.
+type QuoteResult struct {
+	AircraftID                 string  `json:"aircraft_id"`
+	OneWayPriceLow             float64 `json:"one_way_price_low"`
+	OneWayPriceHigh            float64 `json:"one_way_price_high"`
+	BlockMinutes               float64 `json:"block_minutes"`
+	NextAvailableDepartureTick int     `json:"next_available_departure_tick"`
+	SeatsAvailable             int     `json:"seats_available"`
+}
+
+// handleAnalysisQuote answers "can I fly a charter right now, and at what
+// price?" against the caller's current fleet, distinct from
+// handleRouteAnalysis's prospective-route planning over the aircraft
+// catalog. It reuses the haversine + CruiseKmh*0.9 block-speed model from
+// handleRouteAnalysis, but walks each owned aircraft's State/TimerMin/
+// Location to find how soon it could actually reach origin and fly the leg.
+func (s *Server) handleAnalysisQuote(w http.ResponseWriter, r *http.Request) {
+	var req QuoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.Pax <= 0 {
+		req.Pax = 1
+	}
+
+	fromAp, ok1 := s.engine.AirportByIdent(req.Origin)
+	toAp, ok2 := s.engine.AirportByIdent(req.Dest)
+	if !ok1 || !ok2 {
+		writeJSONError(w, http.StatusBadRequest, "invalid airports")
+		return
+	}
+	var viaAp models.Airport
+	hasVia := strings.TrimSpace(req.Via) != ""
+	if hasVia {
+		viaAp, ok1 = s.engine.AirportByIdent(req.Via)
+		if !ok1 {
+			writeJSONError(w, http.StatusBadRequest, "invalid via airport")
+			return
+		}
+	}
+
+	distLeg1 := haversine(fromAp.Latitude, fromAp.Longitude, toAp.Latitude, toAp.Longitude)
+	distLeg2 := 0.0
+	if hasVia {
+		distLeg1 = haversine(fromAp.Latitude, fromAp.Longitude, viaAp.Latitude, viaAp.Longitude)
+		distLeg2 = haversine(viaAp.Latitude, viaAp.Longitude, toAp.Latitude, toAp.Longitude)
+	}
+	totalDist := distLeg1 + distLeg2
+	if totalDist <= 0 {
+		writeJSONError(w, http.StatusBadRequest, "origin and destination must differ")
+		return
+	}
+
+	priceMult := cabinPriceMultiplier["economy"]
+	if m, ok := cabinPriceMultiplier[strings.ToLower(req.Cabin)]; ok {
+		priceMult = m
+	}
+
+	state := s.engine.State(ownerFromContext(r.Context()))
+	originIdent := strings.ToUpper(req.Origin)
+
+	results := []QuoteResult{}
+	for _, ac := range state.Fleet {
+		if ac.Status != "active" || ac.Seats < req.Pax {
+			continue
+		}
+		if ac.RangeKm < distLeg1 || (hasVia && ac.RangeKm < distLeg2) {
+			continue
+		}
+
+		blockSpeed := ac.CruiseKmh * 0.9
+		if blockSpeed <= 0 {
+			blockSpeed = 100
+		}
+		blockMinutes := totalDist / blockSpeed * 60
+
+		waitMin := 0
+		if ac.State == models.AircraftFlying || ac.State == models.AircraftTurnaround {
+			waitMin = ac.TimerMin
+		}
+
+		ferryMin := 0.0
+		if loc := strings.ToUpper(ac.Location); loc != "" && loc != originIdent {
+			if locAp, ok := s.engine.AirportByIdent(loc); ok {
+				ferryDist := haversine(locAp.Latitude, locAp.Longitude, fromAp.Latitude, fromAp.Longitude)
+				ferryMin = ferryDist/blockSpeed*60 + float64(ac.TurnaroundMin)
+			}
+		}
+		nextTick := state.Tick + waitMin + int(math.Ceil(ferryMin))
+
+		price := 0.13 * totalDist * priceMult
+		if price < 50 {
+			price = 50
+		}
+		price *= float64(req.Pax)
+
+		results = append(results, QuoteResult{
+			AircraftID:                 ac.ID,
+			OneWayPriceLow:             price * 0.85,
+			OneWayPriceHigh:            price * 1.25,
+			BlockMinutes:               blockMinutes,
+			NextAvailableDepartureTick: nextTick,
+			SeatsAvailable:             ac.Seats,
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].NextAvailableDepartureTick < results[j].NextAvailableDepartureTick
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(results)
+}
+
 // ===== helpers =====
 
+func atoiDefault(s string, def int) int {
+	if s == "" {
+		return def
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return def
+	}
+	return n
+}
+
 func writeJSONError(w http.ResponseWriter, status int, msg string) {
 	if msg == "" {
 		msg = http.StatusText(status)
@@ -0,0 +1,265 @@
+// Package flightlog records one immutable FlightRecord per departed leg so
+// historical load-factor and profit curves survive past the engine's
+// single-latest-tick Route fields (LastTickRevenue/LastTickLoad).
+package flightlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// FlightRecord is one departed leg, tagged with arbitrary strings (e.g.
+// "curfew_blocked") so queries can slice history by more than just route
+// and time.
+type FlightRecord struct {
+	ID           int64    `json:"id"`
+	Tick         int      `json:"tick"`
+	RouteID      string   `json:"route_id"`
+	Origin       string   `json:"origin"`
+	Dest         string   `json:"dest"`
+	OwnedID      string   `json:"owned_id"`
+	TemplateID   string   `json:"template_id"`
+	BlockMinutes float64  `json:"block_minutes"`
+	Passengers   int      `json:"passengers"`
+	Revenue      float64  `json:"revenue"`
+	Cost         float64  `json:"cost"`
+	Tags         []string `json:"tags,omitempty"`
+}
+
+// Store is an append-only, tag- and tick-indexed log of FlightRecords,
+// persisted as newline-delimited JSON alongside the savegame.
+type Store struct {
+	mu      sync.Mutex
+	path    string
+	records []FlightRecord   // in append order, which is non-decreasing Tick
+	byTag   map[string][]int // tag -> indices into records, in Tick order
+	nextID  int64
+}
+
+// Open loads an existing flight log from path, if any, and returns a Store
+// ready to Append to. An empty path yields an in-memory-only store; a
+// missing file is not an error either way, the log just starts empty.
+func Open(path string) (*Store, error) {
+	s := &Store{path: path, byTag: make(map[string][]int)}
+	if path == "" {
+		return s, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	dec := json.NewDecoder(f)
+	for dec.More() {
+		var rec FlightRecord
+		if err := dec.Decode(&rec); err != nil {
+			return nil, err
+		}
+		s.indexLocked(rec)
+		if rec.ID > s.nextID {
+			s.nextID = rec.ID
+		}
+	}
+	return s, nil
+}
+
+func (s *Store) indexLocked(rec FlightRecord) {
+	idx := len(s.records)
+	s.records = append(s.records, rec)
+	for _, tag := range rec.Tags {
+		s.byTag[tag] = append(s.byTag[tag], idx)
+	}
+}
+
+// Append assigns rec the next ID, persists it, and indexes it for Query and
+// Aggregate.
+func (s *Store) Append(rec FlightRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	rec.ID = s.nextID
+	if s.path != "" {
+		if err := s.appendToDiskLocked(rec); err != nil {
+			return err
+		}
+	}
+	s.indexLocked(rec)
+	return nil
+}
+
+func (s *Store) appendToDiskLocked(rec FlightRecord) error {
+	if dir := filepath.Dir(s.path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// Query narrows the log by origin/dest/aircraft/tag and tick range.
+type Query struct {
+	From, To  string
+	Aircraft  string // matches either OwnedID or TemplateID
+	Tag       string
+	SinceTick int
+	UntilTick int
+	HasSince  bool
+	HasUntil  bool
+	Limit     int
+	Offset    int
+}
+
+// Query returns the matching records (newest first) for the requested page,
+// plus the total match count across all pages. A Tag filter narrows the
+// scan to that tag's index before the tick range is binary-searched, so a
+// tag-and-range query never touches records outside either bound.
+func (s *Store) Query(q Query) ([]FlightRecord, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var candidates []int
+	if q.Tag != "" {
+		candidates = s.byTag[q.Tag]
+	} else {
+		candidates = make([]int, len(s.records))
+		for i := range candidates {
+			candidates[i] = i
+		}
+	}
+
+	lo, hi := 0, len(candidates)
+	if q.HasSince {
+		lo = sort.Search(len(candidates), func(i int) bool {
+			return s.records[candidates[i]].Tick >= q.SinceTick
+		})
+	}
+	if q.HasUntil {
+		hi = sort.Search(len(candidates), func(i int) bool {
+			return s.records[candidates[i]].Tick > q.UntilTick
+		})
+	}
+	if lo > hi {
+		lo = hi
+	}
+
+	var matched []FlightRecord
+	for _, idx := range candidates[lo:hi] {
+		r := s.records[idx]
+		if q.From != "" && !strings.EqualFold(r.Origin, q.From) {
+			continue
+		}
+		if q.To != "" && !strings.EqualFold(r.Dest, q.To) {
+			continue
+		}
+		if q.Aircraft != "" && !strings.EqualFold(r.OwnedID, q.Aircraft) && !strings.EqualFold(r.TemplateID, q.Aircraft) {
+			continue
+		}
+		matched = append(matched, r)
+	}
+
+	for i, j := 0, len(matched)-1; i < j; i, j = i+1, j-1 {
+		matched[i], matched[j] = matched[j], matched[i]
+	}
+	total := len(matched)
+
+	limit := q.Limit
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	offset := q.Offset
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(matched) {
+		return []FlightRecord{}, total
+	}
+	end := offset + limit
+	if end > len(matched) {
+		end = len(matched)
+	}
+	return matched[offset:end], total
+}
+
+// BucketBy selects the granularity Aggregate groups records into.
+type BucketBy string
+
+const (
+	BucketDay   BucketBy = "day"
+	BucketWeek  BucketBy = "week"
+	BucketRoute BucketBy = "route"
+)
+
+// ticksPerDay assumes the engine's 1 tick == 1 simulated minute (see
+// OwnedCraft.TimerMin), so a day bucket is a full 24h of sim time.
+const ticksPerDay = 24 * 60
+
+// AggregateBucket totals one bucket's worth of FlightRecords.
+type AggregateBucket struct {
+	Key        string  `json:"key"`
+	Flights    int     `json:"flights"`
+	Passengers int     `json:"passengers"`
+	Revenue    float64 `json:"revenue"`
+	Cost       float64 `json:"cost"`
+	Profit     float64 `json:"profit"`
+}
+
+// Aggregate buckets every record in the log by day, week, or route and
+// returns per-bucket totals ordered by key.
+func (s *Store) Aggregate(by BucketBy) []AggregateBucket {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	buckets := make(map[string]*AggregateBucket)
+	var order []string
+	keyFor := func(r FlightRecord) string {
+		switch by {
+		case BucketWeek:
+			return fmt.Sprintf("week-%d", r.Tick/(ticksPerDay*7))
+		case BucketRoute:
+			if r.RouteID != "" {
+				return r.RouteID
+			}
+			return strings.ToUpper(r.Origin) + "-" + strings.ToUpper(r.Dest)
+		default:
+			return fmt.Sprintf("day-%d", r.Tick/ticksPerDay)
+		}
+	}
+	for _, r := range s.records {
+		k := keyFor(r)
+		b, ok := buckets[k]
+		if !ok {
+			b = &AggregateBucket{Key: k}
+			buckets[k] = b
+			order = append(order, k)
+		}
+		b.Flights++
+		b.Passengers += r.Passengers
+		b.Revenue += r.Revenue
+		b.Cost += r.Cost
+		b.Profit += r.Revenue - r.Cost
+	}
+	sort.Strings(order)
+	out := make([]AggregateBucket, 0, len(order))
+	for _, k := range order {
+		out = append(out, *buckets[k])
+	}
+	return out
+}
@@ -16,11 +16,13 @@ import (
 	"sync"
 	"time"
 
+	"airline_builder/internal/flightlog"
 	"airline_builder/internal/models"
 )
 
 const (
 	savePath               = "data/savegame.json"
+	flightLogPath          = "data/flightlog.jsonl"
 	manualMaintenanceTicks = 3
 )
 
@@ -38,15 +40,22 @@ type Engine struct {
 	cancel        context.CancelFunc
 	ticker        *time.Ticker
 	savePath      string
+	flightLog     *flightlog.Store
 }
 
 func NewEngine(costs map[string]float64, leads map[string]int) *Engine {
+	fl, err := flightlog.Open(flightLogPath)
+	if err != nil {
+		log.Printf("flightlog: failed to open %s, falling back to in-memory: %v", flightLogPath, err)
+		fl, _ = flightlog.Open("")
+	}
 	return &Engine{
 		byIdent:       make(map[string]models.Airport),
 		rng:           rand.New(rand.NewSource(time.Now().UnixNano())),
 		aircraftCosts: costs,
 		aircraftLead:  leads,
 		savePath:      savePath,
+		flightLog:     fl,
 	}
 }
 
@@ -55,6 +64,19 @@ func (e *Engine) SetSavePath(path string) {
 	e.savePath = path
 }
 
+// SetFlightLogPath switches the flight-track log to path, loading any
+// records already there.
+func (e *Engine) SetFlightLogPath(path string) error {
+	fl, err := flightlog.Open(path)
+	if err != nil {
+		return err
+	}
+	e.mu.Lock()
+	e.flightLog = fl
+	e.mu.Unlock()
+	return nil
+}
+
 func (e *Engine) SetAircraft(list []models.Aircraft) {
 	e.aircraft = list
 }
@@ -97,10 +119,71 @@ func (e *Engine) AirportByIdent(ident string) (models.Airport, bool) {
 	return ap, ok
 }
 
-func (e *Engine) State() models.GameState {
+// State returns the simulation state. With owner == "" (auth disabled) it
+// returns the full shared state as before; with a nonempty owner it returns
+// a copy scoped to that owner's Fleet, Routes, and Cash, so each API key
+// sees only its own airline in a multi-tenant server (see api.AuthConfig).
+func (e *Engine) State(owner string) models.GameState {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if owner == "" {
+		return e.state
+	}
+	scoped := e.state
+	scoped.Cash = e.state.CashByOwner[owner]
+	scoped.CashByOwner = nil
+	scoped.Fleet = nil
+	for _, ac := range e.state.Fleet {
+		if ac.Owner == owner {
+			scoped.Fleet = append(scoped.Fleet, ac)
+		}
+	}
+	scoped.Routes = nil
+	for _, rt := range e.state.Routes {
+		if rt.Owner == owner {
+			scoped.Routes = append(scoped.Routes, rt)
+		}
+	}
+	return scoped
+}
+
+// EnsureOwner seeds owner's cash ledger with startingCash if it doesn't
+// already have a balance, so a freshly minted API key's first /state call
+// sees a funded airline rather than zero cash.
+func (e *Engine) EnsureOwner(owner string, startingCash float64) {
+	if owner == "" {
+		return
+	}
 	e.mu.Lock()
 	defer e.mu.Unlock()
-	return e.state
+	if e.state.CashByOwner == nil {
+		e.state.CashByOwner = make(map[string]float64)
+	}
+	if _, ok := e.state.CashByOwner[owner]; !ok {
+		e.state.CashByOwner[owner] = startingCash
+	}
+}
+
+// cashLocked returns owner's cash balance; owner == "" is the shared
+// single-tenant ledger (e.state.Cash).
+func (e *Engine) cashLocked(owner string) float64 {
+	if owner == "" {
+		return e.state.Cash
+	}
+	return e.state.CashByOwner[owner]
+}
+
+// addCashLocked applies delta to owner's ledger; owner == "" is the shared
+// single-tenant ledger (e.state.Cash).
+func (e *Engine) addCashLocked(owner string, delta float64) {
+	if owner == "" {
+		e.state.Cash += delta
+		return
+	}
+	if e.state.CashByOwner == nil {
+		e.state.CashByOwner = make(map[string]float64)
+	}
+	e.state.CashByOwner[owner] += delta
 }
 
 // LoadAirportsCSV parses an airports CSV and populates the engine.
@@ -137,6 +220,7 @@ func (e *Engine) LoadAirportsCSV(path string) error {
 	cityIdx := idx("municipality")
 	iataIdx := idx("iata_code")
 	icaoIdx := idx("icao_code")
+	tzIdx := idx("timezone")
 
 	var airports []models.Airport
 	for {
@@ -153,6 +237,14 @@ func (e *Engine) LoadAirportsCSV(path string) error {
 		lat, _ := strconv.ParseFloat(rec[latIdx], 64)
 		lon, _ := strconv.ParseFloat(rec[lonIdx], 64)
 
+		tz := ""
+		if tzIdx >= 0 {
+			tz = strings.TrimSpace(rec[tzIdx])
+		}
+		if tz == "" {
+			tz = timezoneForLongitude(lon)
+		}
+
 		airports = append(airports, models.Airport{
 			ID:          rec[idIdx],
 			Ident:       rec[identIdx],
@@ -165,6 +257,7 @@ func (e *Engine) LoadAirportsCSV(path string) error {
 			City:        rec[cityIdx],
 			IATA:        rec[iataIdx],
 			ICAO:        rec[icaoIdx],
+			Timezone:    tz,
 			RunwayM:     runwayMetersForType(t),
 			SlotsPerDay: slotsForType(t),
 			LandingFee:  landingFeeForType(t),
@@ -282,7 +375,7 @@ func (e *Engine) AddRoute(route models.Route) {
 }
 
 // PurchaseAircraft adds a new aircraft order or purchase.
-func (e *Engine) PurchaseAircraft(templateID, mode string) (models.OwnedCraft, error) {
+func (e *Engine) PurchaseAircraft(owner, templateID, mode string) (models.OwnedCraft, error) {
 	ac, err := e.findAircraft(templateID)
 	if err != nil {
 		return models.OwnedCraft{}, err
@@ -318,10 +411,10 @@ func (e *Engine) PurchaseAircraft(templateID, mode string) (models.OwnedCraft, e
 
 	e.mu.Lock()
 	defer e.mu.Unlock()
-	if e.state.Cash < upfront {
+	if e.cashLocked(owner) < upfront {
 		return models.OwnedCraft{}, fmt.Errorf("insufficient cash")
 	}
-	e.state.Cash -= upfront
+	e.addCashLocked(owner, -upfront)
 	newCraft := models.OwnedCraft{
 		ID:            ac.ID + "-" + strconv.FormatInt(time.Now().UnixNano(), 10),
 		TemplateID:    ac.ID,
@@ -339,14 +432,16 @@ func (e *Engine) PurchaseAircraft(templateID, mode string) (models.OwnedCraft, e
 		OwnershipType: ownershipType,
 		MonthlyCost:   monthly,
 		State:         models.AircraftIdle,
+		Owner:         owner,
 	}
 	e.state.Fleet = append(e.state.Fleet, newCraft)
 	e.addEventLocked(fmt.Sprintf("Ordered %s (%s)", newCraft.Name, newCraft.ID))
 	return newCraft, nil
 }
 
-// Maintain performs manual maintenance on an aircraft.
-func (e *Engine) Maintain(ownedID string, manualTicks int) (*models.OwnedCraft, error) {
+// Maintain performs manual maintenance on an aircraft. owner must match the
+// aircraft's Owner unless owner is "" (auth disabled).
+func (e *Engine) Maintain(owner, ownedID string, manualTicks int) (*models.OwnedCraft, error) {
 	if manualTicks < 1 {
 		manualTicks = 3
 	}
@@ -359,20 +454,20 @@ func (e *Engine) Maintain(ownedID string, manualTicks int) (*models.OwnedCraft,
 			break
 		}
 	}
-	if craft == nil {
+	if craft == nil || (owner != "" && craft.Owner != owner) {
 		return nil, fmt.Errorf("unknown aircraft")
 	}
 	if craft.Status == "delivering" {
 		return nil, fmt.Errorf("aircraft still delivering")
 	}
-	if e.state.Cash <= 0 {
+	if e.cashLocked(owner) <= 0 {
 		return nil, fmt.Errorf("insufficient cash")
 	}
 	cost := maintenanceCost(craft.Condition)
-	if e.state.Cash < cost {
+	if e.cashLocked(owner) < cost {
 		return nil, fmt.Errorf("insufficient cash")
 	}
-	e.state.Cash -= cost
+	e.addCashLocked(owner, -cost)
 	craft.Condition = 100
 	craft.State = models.AircraftIdle
 	craft.Status = "active"
@@ -460,7 +555,7 @@ func (e *Engine) PauseSim() {
 }
 
 // BuildRoute calculates a new route using current aircraft and airports.
-func (e *Engine) BuildRoute(from, to, via, aircraftID string, freq int, userPrice float64) (models.Route, error) {
+func (e *Engine) BuildRoute(owner, from, to, via, aircraftID string, freq int, userPrice float64) (models.Route, error) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 	if freq <= 0 {
@@ -546,6 +641,8 @@ func (e *Engine) BuildRoute(from, to, via, aircraftID string, freq int, userPric
 		cost     float64
 		blockMin float64
 		fees     float64
+		origin   string
+		dest     string
 	}
 
 	demandLeg := func(a, b models.Airport, opts demandOptions) int {
@@ -603,6 +700,8 @@ func (e *Engine) BuildRoute(from, to, via, aircraftID string, freq int, userPric
 				cost:     cost,
 				blockMin: legBlock(x.dist),
 				fees:     fees,
+				origin:   x.a.Ident,
+				dest:     x.b.Ident,
 			})
 		}
 	} else {
@@ -628,6 +727,8 @@ func (e *Engine) BuildRoute(from, to, via, aircraftID string, freq int, userPric
 				cost:     cost,
 				blockMin: legBlock(x.dist),
 				fees:     fees,
+				origin:   x.a.Ident,
+				dest:     x.b.Ident,
 			})
 		}
 	}
@@ -654,9 +755,18 @@ func (e *Engine) BuildRoute(from, to, via, aircraftID string, freq int, userPric
 	loadFactor := float64(totalSold) / float64(ac.Seats*len(legs))
 
 	profitPerTick := (totalRevenue - totalCost) * float64(freq)
-	curfewBlocked := fromAp.Curfew || toAp.Curfew
-	if hasVia && viaAp.Curfew {
-		curfewBlocked = true
+
+	legPlans := make([]models.RouteLegPlan, 0, len(legs))
+	for _, l := range legs {
+		legPlans = append(legPlans, models.RouteLegPlan{Origin: l.origin, Dest: l.dest, BlockMinutes: l.blockMin})
+	}
+	schedule := e.localScheduleLocked(legPlans, freq)
+	curfewBlocked := false
+	for _, ls := range schedule {
+		if ls.CurfewConflict {
+			curfewBlocked = true
+			break
+		}
 	}
 
 	avgPricePerSeat := userPrice
@@ -683,6 +793,8 @@ func (e *Engine) BuildRoute(from, to, via, aircraftID string, freq int, userPric
 		CurfewBlocked:     curfewBlocked,
 		LastTickRevenue:   totalRevenue * float64(freq),
 		LastTickLoad:      loadFactor,
+		Schedule:          schedule,
+		Owner:             owner,
 	}
 	return route, nil
 }
@@ -694,9 +806,16 @@ func (e *Engine) AdvanceTick() {
 
 	totalRevenue := 0.0
 	totalCost := 0.0
+	ownerDelta := make(map[string]float64)
 
-	findRouteForAc := func(acID string, tplID string) *models.Route {
+	// findRouteForAc only matches routes owned by the same tenant as the
+	// aircraft, so two owners can each fly an aircraft of the same
+	// TemplateID without their routes colliding (see api.AuthConfig).
+	findRouteForAc := func(owner, acID, tplID string) *models.Route {
 		for i := range e.state.Routes {
+			if e.state.Routes[i].Owner != owner {
+				continue
+			}
 			if strings.EqualFold(e.state.Routes[i].AircraftID, acID) {
 				return &e.state.Routes[i]
 			}
@@ -725,6 +844,7 @@ func (e *Engine) AdvanceTick() {
 			if ac.TimerMin <= 0 {
 				if ac.FlightPlan != nil {
 					ac.Location = strings.ToUpper(ac.FlightPlan.Dest)
+					e.completeBookingsLocked(ac.FlightPlan.FlightInstance)
 				}
 				// enforce a full turnaround before next leg
 				ac.State = models.AircraftTurnaround
@@ -740,7 +860,7 @@ func (e *Engine) AdvanceTick() {
 			if ac.TimerMin > 0 {
 				continue
 			}
-			rt := findRouteForAc(ac.ID, ac.TemplateID)
+			rt := findRouteForAc(ac.Owner, ac.ID, ac.TemplateID)
 			if rt == nil {
 				ac.State = models.AircraftIdle
 				continue
@@ -762,6 +882,7 @@ func (e *Engine) AdvanceTick() {
 
 			totalRevenue += revenue
 			totalCost += cost
+			ownerDelta[ac.Owner] += revenue - cost
 
 			load := 0.0
 			if ac.Seats > 0 {
@@ -776,6 +897,13 @@ func (e *Engine) AdvanceTick() {
 			rt.EstRevenueTick = revenue
 			rt.EstCostTick = cost
 			rt.ProfitPerTick = revenue - cost
+
+			price := 0.0
+			if plan.Passengers > 0 {
+				price = revenue / float64(plan.Passengers)
+			}
+			e.materializeBookingsLocked(rt, plan, price)
+			e.recordFlightLocked(rt, ac, plan, durationMin, revenue, cost)
 		}
 	}
 
@@ -783,14 +911,18 @@ func (e *Engine) AdvanceTick() {
 	for _, ac := range e.state.Fleet {
 		if strings.EqualFold(ac.OwnershipType, "leased") && ac.MonthlyCost > 0 {
 			leaseCost += ac.MonthlyCost
+			ownerDelta[ac.Owner] -= ac.MonthlyCost
 		}
 	}
 	cashDelta := totalRevenue - totalCost - leaseCost
-	e.state.Cash += cashDelta
 	e.state.LastCashDelta = cashDelta
+	for owner, delta := range ownerDelta {
+		e.addCashLocked(owner, delta)
+	}
 
 	e.advanceFleetTimersLocked()
 	e.applyMaintenanceWearLocked()
+	e.recalcBookingStatsLocked()
 	e.state.Tick++
 	if e.state.Tick%6 == 0 {
 		e.recalcUtilizationLocked()
@@ -1024,9 +1156,10 @@ func (e *Engine) planFlightLeg(ac *models.OwnedCraft, rt *models.Route, origin,
 		duration = 1
 	}
 	plan := &models.FlightPlan{
-		Origin:     originID,
-		Dest:       destID,
-		Passengers: sold,
+		Origin:         originID,
+		Dest:           destID,
+		Passengers:     sold,
+		FlightInstance: ac.ID + "-" + strconv.FormatInt(time.Now().UnixNano(), 10),
 	}
 	return duration, plan, revenue, cost
 }
@@ -1144,6 +1277,253 @@ func maintenanceCost(condition float64) float64 {
 	return deficit * 75_000
 }
 
+// maxBookings bounds the Booking history the engine keeps, trimming the
+// oldest entries once exceeded so recalcBookingStatsLocked stays a cheap
+// full scan.
+const maxBookings = 1500
+
+// cancellationChance and noShowChance drive the small fraction of
+// materialized bookings that don't fly: a booking can be cancelled at
+// creation (cancellationChance) or, having been Confirmed, found empty at
+// landing (noShowChance).
+const (
+	cancellationChance = 0.03
+	noShowChance       = 0.04
+)
+
+// materializeBookingsLocked records one Booking per seat sold on a departing
+// flight, driven by plan.Passengers (SeatsSoldPerLeg for this leg). Bookings
+// start Confirmed since the seats are already sold and boarded by the time a
+// flight departs; a small share are immediately Cancelled to model
+// last-minute drop-offs.
+func (e *Engine) materializeBookingsLocked(rt *models.Route, plan *models.FlightPlan, price float64) {
+	if plan == nil || plan.Passengers <= 0 {
+		return
+	}
+	for i := 0; i < plan.Passengers; i++ {
+		b := models.Booking{
+			ID:             fmt.Sprintf("%s-%d", plan.FlightInstance, i),
+			PassengerID:    fmt.Sprintf("pax-%d", e.rng.Int63()),
+			RouteID:        rt.ID,
+			FlightInstance: plan.FlightInstance,
+			PricePaid:      price,
+			Status:         models.BookingConfirmed,
+			CreatedTick:    e.state.Tick,
+		}
+		if e.rng.Float64() < cancellationChance {
+			b.Status = models.BookingCancelled
+		}
+		e.state.Bookings = append(e.state.Bookings, b)
+	}
+	e.trimBookingsLocked()
+}
+
+// recordFlightLocked appends an immutable flightlog.FlightRecord for the leg
+// ac just departed on, so history survives past Route's single-latest-tick
+// fields (LastTickRevenue/LastTickLoad).
+func (e *Engine) recordFlightLocked(rt *models.Route, ac *models.OwnedCraft, plan *models.FlightPlan, durationMin, revenue, cost float64) {
+	if e.flightLog == nil || plan == nil {
+		return
+	}
+	var tags []string
+	if rt.CurfewBlocked {
+		tags = append(tags, "curfew_blocked")
+	}
+	rec := flightlog.FlightRecord{
+		Tick:         e.state.Tick,
+		RouteID:      rt.ID,
+		Origin:       strings.ToUpper(plan.Origin),
+		Dest:         strings.ToUpper(plan.Dest),
+		OwnedID:      ac.ID,
+		TemplateID:   ac.TemplateID,
+		BlockMinutes: durationMin,
+		Passengers:   plan.Passengers,
+		Revenue:      revenue,
+		Cost:         cost,
+		Tags:         tags,
+	}
+	if err := e.flightLog.Append(rec); err != nil {
+		log.Printf("flightlog: append failed: %v", err)
+	}
+}
+
+// completeBookingsLocked advances the Confirmed bookings for a landing
+// flight to CompletedPendingValidation, except for a small share discovered
+// as no-shows and marked Cancelled instead.
+func (e *Engine) completeBookingsLocked(flightInstance string) {
+	if flightInstance == "" {
+		return
+	}
+	for i := range e.state.Bookings {
+		b := &e.state.Bookings[i]
+		if b.FlightInstance != flightInstance || b.Status != models.BookingConfirmed {
+			continue
+		}
+		if e.rng.Float64() < noShowChance {
+			b.Status = models.BookingCancelled
+			b.NoShow = true
+		} else {
+			b.Status = models.BookingCompletedPendingValidation
+		}
+	}
+}
+
+// trimBookingsLocked drops the oldest bookings once maxBookings is
+// exceeded, mirroring addEventLocked's cap on RecentEvents.
+func (e *Engine) trimBookingsLocked() {
+	if len(e.state.Bookings) > maxBookings {
+		e.state.Bookings = e.state.Bookings[len(e.state.Bookings)-maxBookings:]
+	}
+}
+
+// recalcBookingStatsLocked recomputes no-show rate, cancellation rate, and
+// average yield per route and airline-wide from the current Bookings
+// history.
+func (e *Engine) recalcBookingStatsLocked() {
+	type agg struct {
+		total, cancelled, noShow int
+		yieldSum                 float64
+	}
+	perRoute := make(map[string]*agg)
+	global := &agg{}
+	for _, b := range e.state.Bookings {
+		a, ok := perRoute[b.RouteID]
+		if !ok {
+			a = &agg{}
+			perRoute[b.RouteID] = a
+		}
+		for _, x := range [...]*agg{a, global} {
+			x.total++
+			x.yieldSum += b.PricePaid
+			if b.Status == models.BookingCancelled {
+				x.cancelled++
+				if b.NoShow {
+					x.noShow++
+				}
+			}
+		}
+	}
+	statsFor := func(a *agg) models.BookingAggregate {
+		if a == nil || a.total == 0 {
+			return models.BookingAggregate{}
+		}
+		return models.BookingAggregate{
+			NoShowRate:       float64(a.noShow) / float64(a.total),
+			CancellationRate: float64(a.cancelled) / float64(a.total),
+			AvgYield:         a.yieldSum / float64(a.total),
+		}
+	}
+	for i := range e.state.Routes {
+		rt := &e.state.Routes[i]
+		rt.BookingStats = statsFor(perRoute[rt.ID])
+	}
+	e.state.BookingStats = statsFor(global)
+}
+
+// bookingTransitions enumerates the allowed BookingStatus advances:
+// WaitingConfirmation -> Confirmed -> Cancelled / CompletedPendingValidation
+// -> Validated.
+var bookingTransitions = map[models.BookingStatus]map[models.BookingStatus]bool{
+	models.BookingWaitingConfirmation: {
+		models.BookingConfirmed: true,
+		models.BookingCancelled: true,
+	},
+	models.BookingConfirmed: {
+		models.BookingCancelled:                  true,
+		models.BookingCompletedPendingValidation: true,
+	},
+	models.BookingCompletedPendingValidation: {
+		models.BookingValidated: true,
+	},
+}
+
+// CreateBooking records a manual booking (a group sale, an FFP hold) against
+// a route, starting in the WaitingConfirmation state ahead of the engine's
+// own per-departure bookings.
+func (e *Engine) CreateBooking(passengerID, routeID string, price float64) (models.Booking, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var rt *models.Route
+	for i := range e.state.Routes {
+		if e.state.Routes[i].ID == routeID {
+			rt = &e.state.Routes[i]
+			break
+		}
+	}
+	if rt == nil {
+		return models.Booking{}, fmt.Errorf("unknown route")
+	}
+	if price <= 0 {
+		price = rt.UserPrice
+	}
+	b := models.Booking{
+		ID:          fmt.Sprintf("bk-%d", time.Now().UnixNano()),
+		PassengerID: passengerID,
+		RouteID:     routeID,
+		PricePaid:   price,
+		Status:      models.BookingWaitingConfirmation,
+		CreatedTick: e.state.Tick,
+	}
+	e.state.Bookings = append(e.state.Bookings, b)
+	e.trimBookingsLocked()
+	e.recalcBookingStatsLocked()
+	return b, nil
+}
+
+// UpdateBookingStatus advances a booking to a new status, rejecting any
+// transition not allowed by bookingTransitions.
+func (e *Engine) UpdateBookingStatus(id string, status models.BookingStatus) (models.Booking, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for i := range e.state.Bookings {
+		b := &e.state.Bookings[i]
+		if b.ID != id {
+			continue
+		}
+		if !bookingTransitions[b.Status][status] {
+			return models.Booking{}, fmt.Errorf("cannot transition booking from %s to %s", b.Status, status)
+		}
+		b.Status = status
+		e.recalcBookingStatsLocked()
+		return *b, nil
+	}
+	return models.Booking{}, fmt.Errorf("unknown booking")
+}
+
+// ListBookings returns bookings optionally filtered by route and/or status.
+func (e *Engine) ListBookings(routeID string, status models.BookingStatus) []models.Booking {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	out := make([]models.Booking, 0)
+	for _, b := range e.state.Bookings {
+		if routeID != "" && b.RouteID != routeID {
+			continue
+		}
+		if status != "" && b.Status != status {
+			continue
+		}
+		out = append(out, b)
+	}
+	return out
+}
+
+// Flights returns a page of flightlog.FlightRecords matching q, plus the
+// total match count across all pages.
+func (e *Engine) Flights(q flightlog.Query) ([]flightlog.FlightRecord, int) {
+	if e.flightLog == nil {
+		return []flightlog.FlightRecord{}, 0
+	}
+	return e.flightLog.Query(q)
+}
+
+// FlightsAggregate buckets the flight-track log by day, week, or route.
+func (e *Engine) FlightsAggregate(by flightlog.BucketBy) []flightlog.AggregateBucket {
+	if e.flightLog == nil {
+		return nil
+	}
+	return e.flightLog.Aggregate(by)
+}
+
 func intervalForSpeed(speed int) time.Duration {
 	switch speed {
 	case 1:
@@ -1232,21 +1612,167 @@ func curfewForType(t string) bool {
 	return curfewAppliesTo[t]
 }
 
-func curfewAvailableMinutes(startHour, endHour int) float64 {
-	if startHour == endHour {
-		return 24 * 60
+// timezoneForLongitude estimates an IANA zone from longitude alone, for
+// airports.csv rows with no timezone column: every 15 degrees is treated as
+// one hour of offset from UTC, resolved to a fixed "Etc/GMT" zone (note
+// those zones use signs inverted from the usual east-positive convention).
+// This is an approximation - real zone boundaries follow borders, not
+// meridians - but it's enough to get curfew checks into the right ballpark
+// without a geocoding dependency.
+func timezoneForLongitude(lon float64) string {
+	offset := int(math.Round(lon / 15))
+	if offset > 12 {
+		offset = 12
 	}
-	blocked := 0
-	if startHour < endHour {
-		blocked = endHour - startHour
-	} else {
-		blocked = (24 - startHour) + endHour
+	if offset < -12 {
+		offset = -12
+	}
+	if offset == 0 {
+		return "UTC"
+	}
+	if offset > 0 {
+		return fmt.Sprintf("Etc/GMT-%d", offset)
+	}
+	return fmt.Sprintf("Etc/GMT+%d", -offset)
+}
+
+// scheduleReferenceDateFunc returns the calendar date (time-of-day
+// discarded) the synthetic UTC clock anchors to when resolving local
+// hours. It's a var, not a hardcoded date, so DST-observing IANA zones -
+// e.g. America/Los_Angeles - resolve against the correct summer/winter
+// offset for whatever date is current instead of one hardcoded date's
+// offset year-round; tests can swap it in for a fixed, deterministic date.
+var scheduleReferenceDateFunc = func() time.Time {
+	return time.Now().UTC().Truncate(24 * time.Hour)
+}
+
+func referenceClock(hourUTC int) time.Time {
+	return scheduleReferenceDateFunc().Add(time.Duration(hourUTC) * time.Hour)
+}
+
+func localHour(t time.Time, tz string) int {
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		loc = time.UTC
+	}
+	return t.In(loc).Hour()
+}
+
+func localClockString(t time.Time, tz string) string {
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		loc = time.UTC
+	}
+	return t.In(loc).Format("15:04 MST")
+}
+
+// isCurfewHour reports whether hour (0-23, local to ap) falls inside ap's
+// curfew window, handling windows that wrap midnight (e.g. 23:00-06:00).
+// CurfewStart == CurfewEnd is treated as "no restriction", mirroring how
+// the pre-timezone curfewAvailableMinutes check read that case.
+func isCurfewHour(ap models.Airport, hour int) bool {
+	if !ap.Curfew || ap.CurfewStart == ap.CurfewEnd {
+		return false
+	}
+	if ap.CurfewStart < ap.CurfewEnd {
+		return hour >= ap.CurfewStart && hour < ap.CurfewEnd
 	}
-	openHours := 24 - blocked
-	if openHours < 0 {
-		openHours = 0
+	return hour >= ap.CurfewStart || hour < ap.CurfewEnd
+}
+
+// openHoursUTC returns the UTC hours (0-23) during which none of the given
+// airports are under their local curfew.
+func openHoursUTC(airports []models.Airport) []int {
+	var open []int
+	for h := 0; h < 24; h++ {
+		blocked := false
+		for _, ap := range airports {
+			if isCurfewHour(ap, localHour(referenceClock(h), ap.Timezone)) {
+				blocked = true
+				break
+			}
+		}
+		if !blocked {
+			open = append(open, h)
+		}
 	}
-	return float64(openHours) * 60
+	return open
+}
+
+// pickDepartureHours spreads freq departures evenly across the open hours.
+// If freq exceeds len(open) (or open is empty), it falls back to spreading
+// across the full day so BuildRoute can still return a best-effort
+// schedule - ValidateCapacity is what actually rejects a frequency that
+// can't fit outside curfew.
+func pickDepartureHours(open []int, freq int) []int {
+	if freq <= 0 {
+		freq = 1
+	}
+	pool := open
+	if len(pool) == 0 || freq > len(pool) {
+		pool = make([]int, 24)
+		for i := range pool {
+			pool[i] = i
+		}
+	}
+	hours := make([]int, freq)
+	step := float64(len(pool)) / float64(freq)
+	for i := range hours {
+		hours[i] = pool[int(float64(i)*step)%len(pool)]
+	}
+	return hours
+}
+
+// LocalSchedule assigns each leg a UTC departure hour (spread across the
+// hours open at every airport the legs touch, see openHoursUTC) and returns
+// the resulting local departure/arrival clocks.
+func (e *Engine) LocalSchedule(legs []models.RouteLegPlan, freq int) []models.LegSchedule {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.localScheduleLocked(legs, freq)
+}
+
+func (e *Engine) localScheduleLocked(legs []models.RouteLegPlan, freq int) []models.LegSchedule {
+	if len(legs) == 0 {
+		return nil
+	}
+	var airportsInvolved []models.Airport
+	seen := make(map[string]bool)
+	for _, l := range legs {
+		for _, ident := range [...]string{l.Origin, l.Dest} {
+			ident = strings.ToUpper(ident)
+			if ident == "" || seen[ident] {
+				continue
+			}
+			if ap, ok := e.byIdent[ident]; ok {
+				airportsInvolved = append(airportsInvolved, ap)
+				seen[ident] = true
+			}
+		}
+	}
+	hours := pickDepartureHours(openHoursUTC(airportsInvolved), freq)
+
+	out := make([]models.LegSchedule, 0, len(legs))
+	for i, l := range legs {
+		hourUTC := hours[i%len(hours)]
+		depTime := referenceClock(hourUTC)
+		arrTime := depTime.Add(time.Duration(l.BlockMinutes) * time.Minute)
+		ls := models.LegSchedule{Origin: l.Origin, Dest: l.Dest, DepartureHourUTC: hourUTC}
+		if ap, ok := e.byIdent[strings.ToUpper(l.Origin)]; ok {
+			ls.DepartureLocal = localClockString(depTime, ap.Timezone)
+			if isCurfewHour(ap, localHour(depTime, ap.Timezone)) {
+				ls.CurfewConflict = true
+			}
+		}
+		if ap, ok := e.byIdent[strings.ToUpper(l.Dest)]; ok {
+			ls.ArrivalLocal = localClockString(arrTime, ap.Timezone)
+			if isCurfewHour(ap, localHour(arrTime, ap.Timezone)) {
+				ls.CurfewConflict = true
+			}
+		}
+		out = append(out, ls)
+	}
+	return out
 }
 
 // DemandEstimate is a public wrapper.
@@ -1297,38 +1823,38 @@ func (e *Engine) ValidateCapacity(route models.Route) error {
 		}
 	}
 
-	blockUse := make(map[string]float64)
-	addBlockUse := func(ident string, mins float64, freq int, blockUse map[string]float64) {
-		if ident == "" || freq == 0 || mins <= 0 {
-			return
-		}
-		blockUse[strings.ToUpper(ident)] += mins * float64(freq)
-	}
-	addBlockUse(route.From, route.BlockMinutes, route.FrequencyPerDay, blockUse)
-	addBlockUse(route.To, route.BlockMinutes, route.FrequencyPerDay, blockUse)
-	addBlockUse(route.Via, route.BlockMinutes, route.FrequencyPerDay, blockUse)
-	for _, rt := range e.state.Routes {
-		addBlockUse(rt.From, rt.BlockMinutes, rt.FrequencyPerDay, blockUse)
-		addBlockUse(rt.To, rt.BlockMinutes, rt.FrequencyPerDay, blockUse)
-		addBlockUse(rt.Via, rt.BlockMinutes, rt.FrequencyPerDay, blockUse)
-	}
-	for ident, mins := range blockUse {
-		ap, ok := e.byIdent[ident]
-		if !ok || !ap.Curfew {
+	// A route's frequency can only be scheduled into the local hours, at
+	// every airport it touches, that aren't under that airport's curfew
+	// (see localScheduleLocked/openHoursUTC for the timezone-aware check).
+	var routeAirports []models.Airport
+	for _, ident := range []string{route.From, route.To, route.Via} {
+		if ident == "" {
 			continue
 		}
-		avail := curfewAvailableMinutes(ap.CurfewStart, ap.CurfewEnd)
-		if mins > avail {
-			return fmt.Errorf("curfew hours limit at %s (%.0f/%.0f mins)", ident, mins, avail)
+		if ap, ok := e.byIdent[strings.ToUpper(ident)]; ok {
+			routeAirports = append(routeAirports, ap)
+		}
+	}
+	open := openHoursUTC(routeAirports)
+	if route.FrequencyPerDay > len(open) {
+		label := route.From + "-" + route.To
+		if route.Via != "" {
+			label += " via " + route.Via
 		}
+		return fmt.Errorf("frequency of %d/day at %s cannot avoid local curfew windows (only %d of 24 local hours are open)", route.FrequencyPerDay, label, len(open))
 	}
 	return nil
 }
 
-// MarketExists reports if a route already exists in either direction.
-func (e *Engine) MarketExists(from, to string) bool {
+// MarketExists reports whether owner already serves the from/to market in
+// either direction. Each multi-tenant owner has its own markets, so two
+// airlines may both fly the same city pair.
+func (e *Engine) MarketExists(owner, from, to string) bool {
 	key := marketKey(from, to)
 	for _, rt := range e.state.Routes {
+		if rt.Owner != owner {
+			continue
+		}
 		if marketKey(rt.From, rt.To) == key {
 			return true
 		}
@@ -1,17 +1,22 @@
 package models
 
 type Airport struct {
-	ID          string  `json:"id"`
-	Ident       string  `json:"ident"`
-	Type        string  `json:"type"`
-	Name        string  `json:"name"`
-	Latitude    float64 `json:"lat"`
-	Longitude   float64 `json:"lon"`
-	Country     string  `json:"country"`
-	Region      string  `json:"region"`
-	City        string  `json:"city"`
-	IATA        string  `json:"iata"`
-	ICAO        string  `json:"icao"`
+	ID        string  `json:"id"`
+	Ident     string  `json:"ident"`
+	Type      string  `json:"type"`
+	Name      string  `json:"name"`
+	Latitude  float64 `json:"lat"`
+	Longitude float64 `json:"lon"`
+	Country   string  `json:"country"`
+	Region    string  `json:"region"`
+	City      string  `json:"city"`
+	IATA      string  `json:"iata"`
+	ICAO      string  `json:"icao"`
+	// Timezone is the airport's IANA zone (e.g. "America/Los_Angeles"),
+	// used by the engine to evaluate curfews and schedules in local time
+	// instead of bare UTC hours. Populated from a "timezone" CSV column if
+	// present, otherwise estimated from longitude.
+	Timezone    string  `json:"timezone,omitempty"`
 	RunwayM     int     `json:"runway_m"`
 	SlotsPerDay int     `json:"slots_per_day"`
 	LandingFee  float64 `json:"landing_fee"`
@@ -73,6 +78,80 @@ type Route struct {
 	CurfewBlocked     bool    `json:"curfew_blocked"`
 	LastTickRevenue   float64 `json:"last_tick_revenue"`
 	LastTickLoad      float64 `json:"last_tick_load"`
+
+	// BookingStats aggregates the Booking records materialized for this
+	// route by the engine's per-tick booking lifecycle (see
+	// Engine.recalcBookingStatsLocked).
+	BookingStats BookingAggregate `json:"booking_stats,omitempty"`
+
+	// Schedule is the local-time departure/arrival plan the engine assigned
+	// each leg of this route (see Engine.LocalSchedule); CurfewBlocked is
+	// true if any leg in it could not avoid a local curfew window.
+	Schedule []LegSchedule `json:"schedule,omitempty"`
+
+	// Owner is the API key owner this route belongs to in multi-tenant
+	// play (see api.AuthConfig); empty when auth is disabled.
+	Owner string `json:"owner,omitempty"`
+}
+
+// RouteLegPlan describes one leg to schedule: its endpoints and the block
+// time (including turnaround) it occupies, used by Engine.LocalSchedule to
+// derive a local arrival clock from an assigned UTC departure hour.
+type RouteLegPlan struct {
+	Origin       string  `json:"origin"`
+	Dest         string  `json:"dest"`
+	BlockMinutes float64 `json:"block_minutes"`
+}
+
+// LegSchedule is one leg's assigned departure hour and the resulting local
+// departure/arrival clock times at its two endpoints.
+type LegSchedule struct {
+	Origin           string `json:"origin"`
+	Dest             string `json:"dest"`
+	DepartureHourUTC int    `json:"departure_hour_utc"`
+	DepartureLocal   string `json:"departure_local"`
+	ArrivalLocal     string `json:"arrival_local"`
+	// CurfewConflict is true if the local departure or arrival hour falls
+	// inside either endpoint's curfew window.
+	CurfewConflict bool `json:"curfew_conflict,omitempty"`
+}
+
+// BookingStatus is the lifecycle stage of a Booking.
+type BookingStatus string
+
+const (
+	BookingWaitingConfirmation        BookingStatus = "waiting_confirmation"
+	BookingConfirmed                  BookingStatus = "confirmed"
+	BookingCancelled                  BookingStatus = "cancelled"
+	BookingCompletedPendingValidation BookingStatus = "completed_pending_validation"
+	BookingValidated                  BookingStatus = "validated"
+)
+
+// Booking is a single passenger's seat on a route, tracked at per-seat
+// granularity rather than the per-tick aggregates on Route. Most bookings
+// are materialized automatically by the engine when a flight departs
+// (Engine.materializeBookingsLocked); POST /bookings lets callers record
+// manual bookings (group sales, FFP holds) ahead of that.
+type Booking struct {
+	ID             string        `json:"id"`
+	PassengerID    string        `json:"passenger_id"`
+	RouteID        string        `json:"route_id"`
+	FlightInstance string        `json:"flight_instance,omitempty"`
+	PricePaid      float64       `json:"price_paid"`
+	Status         BookingStatus `json:"status"`
+	// NoShow marks a Cancelled booking that was Confirmed (seat held) but
+	// discovered empty at landing, as distinct from a pre-departure
+	// cancellation; it is what BookingAggregate.NoShowRate is computed from.
+	NoShow      bool `json:"no_show,omitempty"`
+	CreatedTick int  `json:"created_tick"`
+}
+
+// BookingAggregate summarizes a set of bookings for a route or the whole
+// airline.
+type BookingAggregate struct {
+	NoShowRate       float64 `json:"no_show_rate"`
+	CancellationRate float64 `json:"cancellation_rate"`
+	AvgYield         float64 `json:"avg_yield"`
 }
 
 type GameState struct {
@@ -82,6 +161,24 @@ type GameState struct {
 	Tick      int          `json:"tick"`
 	IsRunning bool         `json:"is_running"`
 	Speed     int          `json:"speed"`
+
+	Bookings     []Booking        `json:"bookings,omitempty"`
+	BookingStats BookingAggregate `json:"booking_stats,omitempty"`
+
+	// CashByOwner holds each multi-tenant owner's cash balance, keyed by
+	// API key owner (see api.AuthConfig). Cash remains the single-tenant
+	// ledger used when auth is disabled and every Route/OwnedCraft has an
+	// empty Owner.
+	CashByOwner map[string]float64 `json:"cash_by_owner,omitempty"`
+
+	// RecentEvents is a capped log of human-readable tick events (see
+	// addEventLocked), most recent last.
+	RecentEvents []string `json:"recent_events,omitempty"`
+	// DemandVariability is the stddev, as a fraction of baseline demand,
+	// used to jitter route demand each tick.
+	DemandVariability float64 `json:"demand_variability"`
+	// LastCashDelta is the net cash change applied on the most recent tick.
+	LastCashDelta float64 `json:"last_cash_delta"`
 }
 
 type AircraftState string
@@ -90,12 +187,14 @@ const (
 	AircraftIdle       AircraftState = "idle"
 	AircraftFlying     AircraftState = "flying"
 	AircraftTurnaround AircraftState = "turnaround"
+	AircraftGrounded   AircraftState = "grounded"
 )
 
 type FlightPlan struct {
-	Origin     string `json:"origin"`
-	Dest       string `json:"dest"`
-	Passengers int    `json:"passengers"`
+	Origin         string `json:"origin"`
+	Dest           string `json:"dest"`
+	Passengers     int    `json:"passengers"`
+	FlightInstance string `json:"flight_instance,omitempty"`
 }
 
 type OwnedCraft struct {
@@ -122,4 +221,8 @@ type OwnedCraft struct {
 	TimerMin      int           `json:"timer_min"`
 	FlightPlan    *FlightPlan   `json:"flight_plan,omitempty"`
 	RouteLegIndex int           `json:"route_leg_index,omitempty"`
+
+	// Owner is the API key owner this aircraft belongs to in multi-tenant
+	// play (see api.AuthConfig); empty when auth is disabled.
+	Owner string `json:"owner,omitempty"`
 }
@@ -11,32 +11,56 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/go-chi/chi/v5"
+
+	"airline_builder/pkg/analytics"
+	"airline_builder/pkg/demand"
+	"airline_builder/pkg/fleetplan"
+	"airline_builder/pkg/flightops"
+	"airline_builder/pkg/gtfs"
+	"airline_builder/pkg/gtfsrt"
+	"airline_builder/pkg/netplan"
+	"airline_builder/pkg/routeplan"
+	"airline_builder/pkg/scheduler"
 )
 
+// The HTTP surface wired up in main() below is described formally in
+// api/openapi.yaml. The directive below names oapi-codegen and an
+// api/oapi-codegen.yaml config, neither of which is in the tree, so it does
+// not run and there is no internal/api/gen package; request decoding and
+// validation are the hand-rolled json.Decode blocks in the handlers here.
+//go:generate go run github.com/deepmap/oapi-codegen/cmd/oapi-codegen --config api/oapi-codegen.yaml api/openapi.yaml
+
 type Airport struct {
-	ID          string  `json:"id"`
-	Ident       string  `json:"ident"`
-	Type        string  `json:"type"`
-	Name        string  `json:"name"`
-	Latitude    float64 `json:"lat"`
-	Longitude   float64 `json:"lon"`
-	Country     string  `json:"country"`
-	Region      string  `json:"region"`
-	City        string  `json:"city"`
-	IATA        string  `json:"iata"`
-	ICAO        string  `json:"icao"`
-	RunwayM     int     `json:"runway_m"`
-	SlotsPerDay int     `json:"slots_per_day"`
-	LandingFee  float64 `json:"landing_fee"`
-	Curfew      bool    `json:"curfew"`
-	CurfewStart int     `json:"curfew_start_hour"`
-	CurfewEnd   int     `json:"curfew_end_hour"`
+	ID           string   `json:"id"`
+	Ident        string   `json:"ident"`
+	Type         string   `json:"type"`
+	Name         string   `json:"name"`
+	Latitude     float64  `json:"lat"`
+	Longitude    float64  `json:"lon"`
+	Country      string   `json:"country"`
+	Region       string   `json:"region"`
+	City         string   `json:"city"`
+	IATA         string   `json:"iata"`
+	ICAO         string   `json:"icao"`
+	RunwayM      int      `json:"runway_m"`
+	SlotsPerHour [24]int  `json:"slots_per_hour"`
+	LandingFee   float64  `json:"landing_fee"`
+	Curfew       bool     `json:"curfew"`
+	CurfewStart  int      `json:"curfew_start_hour"`
+	CurfewEnd    int      `json:"curfew_end_hour"`
+
+	// CurfewHours is the per-hour open/closed mask derived from
+	// CurfewStart/CurfewEnd (true = closed), kept independently so a split
+	// or mid-day noise-abatement curfew can someday override individual
+	// hours without a single start/end pair being able to express it.
+	CurfewHours [24]bool `json:"curfew_hours"`
 }
 
 type AirportStore struct {
@@ -96,6 +120,128 @@ type Route struct {
 	CurfewBlocked     bool    `json:"curfew_blocked"`
 	LastTickRevenue   float64 `json:"last_tick_revenue"`
 	LastTickLoad      float64 `json:"last_tick_load"`
+
+	// DepartureBank is the hour-of-day bucket (0-23) this route's entire
+	// FrequencyPerDay is scheduled into for slot/curfew accounting and for
+	// advanceTickLocked to gate departures on. Picked at creation time from
+	// the hour with the richest demand (peakDepartureBank).
+	DepartureBank int `json:"departure_bank"`
+
+	// DepartureHours optionally spreads FrequencyPerDay's departures across
+	// specific hours-of-day (one entry per daily frequency) for the slot and
+	// curfew checks in validateCapacityLocked, instead of bunching them all
+	// into DepartureBank. See effectiveDepartureHours. Dispatch in
+	// advanceTickLocked and accrueLostProfitLocked gate on DepartureBank
+	// alone and do not read this field. Empty on routes built before this
+	// existed, which keeps the single-DepartureBank behavior.
+	DepartureHours []int `json:"departure_hours,omitempty"`
+
+	// AssignedCraftIDs are the specific OwnedCraft (not just template) that
+	// were rostered onto this route at creation time, earliest-available
+	// first. The tick loop drives only these instances; routes loaded from
+	// an old savegame with no assignment fall back to template matching.
+	AssignedCraftIDs []string `json:"assigned_craft_ids,omitempty"`
+
+	// LostProfit is the rolling sum of LostProfitHistory: the counterfactual
+	// revenue-cost this route would have earned on ticks where it skipped
+	// its scheduled departure (grounded aircraft, curfew, or slot cap).
+	LostProfit float64 `json:"lost_profit"`
+
+	// LostProfitHistory holds up to lostProfitWindow entries, oldest first,
+	// so /routes/{id}/lost can show a trend rather than just a total.
+	LostProfitHistory []float64 `json:"lost_profit_history,omitempty"`
+
+	// AutoManage enables the trailing-stop/ATR auto-repricing controller in
+	// autoManageRouteLocked; K, Window and Callback are its tunables, set via
+	// POST /routes/{id}/automanage and stored as sent; autoManageRouteLocked
+	// uses the defaultAutoManage* constants in place of a zero value.
+	AutoManage         bool    `json:"auto_manage"`
+	AutoManageK        float64 `json:"auto_manage_k,omitempty"`
+	AutoManageWindow   int     `json:"auto_manage_window,omitempty"`
+	AutoManageCallback float64 `json:"auto_manage_callback,omitempty"`
+
+	// Suspended is set by autoManageRouteLocked when cumulative profit over
+	// the window falls below the trailing-stop callback; advanceTickLocked
+	// stops dispatching a suspended route until it's re-enabled.
+	Suspended bool `json:"suspended"`
+
+	// LoadHistory and ProfitHistory are ring buffers of the last
+	// AutoManageWindow departures' LastTickLoad/ProfitPerTick, oldest first.
+	LoadHistory   []float64 `json:"load_history,omitempty"`
+	ProfitHistory []float64 `json:"profit_history,omitempty"`
+
+	// LoadATR, PeakProfit and TroughProfit are computed from LoadHistory and
+	// ProfitHistory by autoManageRouteLocked, and surfaced so users can see
+	// why the auto-manager cut, raised, or suspended.
+	LoadATR      float64 `json:"load_atr"`
+	PeakProfit   float64 `json:"peak_profit"`
+	TroughProfit float64 `json:"trough_profit"`
+
+	// YieldManage enables the trailing-ATR dynamic yield-management
+	// controller in yieldManageRouteLocked, an independent pricing mode from
+	// AutoManage: instead of cutting price on a falling profit trend, it
+	// nudges UserPrice off configurable trailing-load bands. Set via POST
+	// /routes/{id}/yield.
+	YieldManage  bool    `json:"yield_manage,omitempty"`
+	YieldWindow  int     `json:"yield_window,omitempty"`
+	YieldK       float64 `json:"yield_k,omitempty"`
+	YieldMinFare float64 `json:"yield_min_fare,omitempty"`
+	YieldMaxFare float64 `json:"yield_max_fare,omitempty"`
+
+	// TrailingActivationRatio and TrailingCallbackRate are parallel band
+	// lists: once the trailing load crosses TrailingActivationRatio[i]
+	// going up, UserPrice is raised by (i+1)*YieldK*YieldATR*UserPrice; once it falls
+	// below TrailingCallbackRate[i], UserPrice is cut by the same step.
+	// Multiple entries let a route layer bands (e.g. raise faster above
+	// 0.95 than above 0.85).
+	TrailingActivationRatio []float64 `json:"trailing_activation_ratio,omitempty"`
+	TrailingCallbackRate    []float64 `json:"trailing_callback_rate,omitempty"`
+
+	// YieldLoadWindow is the trailing window of LastTickLoad samples
+	// yieldManageRouteLocked computes YieldATR from. Kept separate from
+	// AutoManage's own LoadHistory so the two pricing modes can run with
+	// different window lengths independently.
+	YieldLoadWindow []float64 `json:"yield_load_window,omitempty"`
+	YieldATR        float64   `json:"yield_atr,omitempty"`
+
+	// CargoCommodity is the commodityCatalog ID this route hauls in its
+	// hold alongside passengers, or "" if it carries no cargo. CargoHoldKg
+	// is the kg of hold space set aside for it per leg, defaulted in
+	// buildRoute from the aircraft's MaxPayloadKg.
+	CargoCommodity string  `json:"cargo_commodity,omitempty"`
+	CargoHoldKg    float64 `json:"cargo_hold_kg,omitempty"`
+
+	// LastTickCargoRevenue and CargoProfitPerTick are applyCargoLegLocked's
+	// most recent sale, tracked separately from passenger revenue so
+	// /routes/{id}/cargo can report cargo profitability on its own.
+	LastTickCargoRevenue float64 `json:"last_tick_cargo_revenue,omitempty"`
+	CargoProfitPerTick   float64 `json:"cargo_profit_per_tick,omitempty"`
+
+	// PriceDiscoveryActive marks a grid price-discovery probe as running,
+	// started via POST /routes/{id}/price-discovery. While active,
+	// priceDiscoveryRouteLocked takes over UserPrice from
+	// AutoManage/YieldManage, walking it through PriceDiscoveryLevels.
+	PriceDiscoveryActive bool `json:"price_discovery_active,omitempty"`
+
+	// PriceDiscoveryLevels are the price points probed, one
+	// price*(1+k*deviation) per step for k spaced evenly around the
+	// midpoint price the probe was started at (see
+	// runPriceDiscoveryProbeLocked). PriceDiscoverySold and
+	// PriceDiscoveryHits are parallel slices recording, per level, the
+	// passengers sold and number of departures observed so far, which
+	// fitDemandCurve turns into a linear (a, b) demand curve once the
+	// probe finishes.
+	PriceDiscoveryLevels []float64 `json:"price_discovery_levels,omitempty"`
+	PriceDiscoverySold   []int     `json:"price_discovery_sold,omitempty"`
+	PriceDiscoveryHits   []int     `json:"price_discovery_hits,omitempty"`
+
+	// PriceDiscoveryStep indexes the level in PriceDiscoveryLevels
+	// currently being probed; priceDiscoveryRouteLocked holds it for
+	// PriceDiscoveryTicksPerStep departures (counted in
+	// PriceDiscoveryTicksInStep) before advancing.
+	PriceDiscoveryStep         int `json:"price_discovery_step,omitempty"`
+	PriceDiscoveryTicksPerStep int `json:"price_discovery_ticks_per_step,omitempty"`
+	PriceDiscoveryTicksInStep  int `json:"price_discovery_ticks_in_step,omitempty"`
 }
 
 type GameState struct {
@@ -105,6 +251,492 @@ type GameState struct {
 	Tick      int          `json:"tick"`
 	IsRunning bool         `json:"is_running"`
 	Speed     int          `json:"speed"`
+
+	// LostProfitTick is this tick's total counterfactual revenue-cost
+	// across every route that skipped its scheduled departure (grounded
+	// aircraft, curfew, or slot contention). See accrueLostProfitLocked.
+	LostProfitTick float64 `json:"lost_profit_tick"`
+
+	FuelMarket FuelMarket `json:"fuel_market"`
+
+	CommodityMarket CommodityMarket `json:"commodity_market"`
+
+	// Subsidies are the government revenue-bonus offers tracked by
+	// offerSubsidiesLocked/applySubsidyLocked; see the Subsidy doc comment.
+	Subsidies []Subsidy `json:"subsidies,omitempty"`
+
+	// Alerts are the live and past service disruptions tracked by
+	// publishAlertLocked/maybeGenerateDisruptionLocked; see the Alert doc
+	// comment.
+	Alerts []Alert `json:"alerts,omitempty"`
+
+	// MarketDemandCurves holds the empirical linear demand curve fitted by
+	// the most recent runPriceDiscoveryProbeLocked run for a market, keyed
+	// by marketKey(from, to). See the DemandCurve doc comment.
+	MarketDemandCurves map[string]DemandCurve `json:"market_demand_curves,omitempty"`
+}
+
+// DemandCurve is a linear fit sold = A + B*price over a market, produced by
+// fitDemandCurve from a completed price-discovery probe's per-level
+// samples. demandEstimateWithOpts prefers it over the synthetic
+// gravity/logit estimator once Samples reaches minDemandCurveSamples, since
+// it reflects this market's observed price sensitivity rather than a
+// population model.
+type DemandCurve struct {
+	A       float64 `json:"a"`
+	B       float64 `json:"b"`
+	Samples int     `json:"samples"`
+}
+
+// FuelMarket is the global fuel-price multiplier applied to every
+// ac.FuelCost consumption, evolved each tick as a mean-reverting random
+// walk (see evolveFuelMarketLocked) and optionally hedged via /fuel/hedge.
+type FuelMarket struct {
+	Price  float64     `json:"price"` // current multiplier, clamped to [0.5, 2.5]
+	Theta  float64     `json:"theta"` // mean-reversion speed
+	Mu     float64     `json:"mu"`    // long-run mean multiplier
+	Sigma  float64     `json:"sigma"` // per-tick volatility
+	Hedges []FuelHedge `json:"hedges,omitempty"`
+}
+
+// FuelHedge locks LockedFraction of fuel consumption at LockedPrice (the
+// market multiplier at purchase time) until ExpiresAtTick, in exchange for
+// an up-front Premium. RealizedSavings accumulates, tick by tick, the
+// difference between what that locked fraction would have cost at the
+// live spot price versus the locked price.
+type FuelHedge struct {
+	Mode            string  `json:"mode"`
+	LockedFraction  float64 `json:"locked_fraction"`
+	LockedPrice     float64 `json:"locked_price"`
+	Premium         float64 `json:"premium"`
+	ExpiresAtTick   int     `json:"expires_at_tick"`
+	RealizedSavings float64 `json:"realized_savings"`
+}
+
+// defaultFuelMarket seeds a new game (or an old savegame predating this
+// subsystem) with a multiplier centered on 1.0, i.e. no change from the
+// nominal FuelCost figures already in data/aircraft.json.
+func defaultFuelMarket() FuelMarket {
+	return FuelMarket{Price: 1.0, Theta: 0.1, Mu: 1.0, Sigma: 0.05}
+}
+
+// Commodity is a catalog entry for one kind of freight a route can haul in
+// its hold alongside passengers, mirroring FuelMarket's mean-reverting
+// design but with an independent spot price per airport rather than one
+// global multiplier.
+type Commodity struct {
+	ID            string  `json:"id"`
+	Name          string  `json:"name"`
+	HaulCostPerKg float64 `json:"haul_cost_per_kg_km"` // cost to move 1kg 1km
+	BasePrice     float64 `json:"base_price"` // long-run mean spot price per kg
+	Theta         float64 `json:"theta"`      // mean-reversion speed
+	Sigma         float64 `json:"sigma"`      // per-tick volatility
+}
+
+// commodityCatalog is the fixed set of freight types routes can be
+// configured to haul. Unlike aircraftCatalog it has no data file backing
+// it yet; these are reasonable starting figures, not sourced prices.
+var commodityCatalog = []Commodity{
+	{ID: "mail", Name: "Mail", HaulCostPerKg: 0.0015, BasePrice: 1.10, Theta: 0.05, Sigma: 0.03},
+	{ID: "express", Name: "Express Parcels", HaulCostPerKg: 0.0035, BasePrice: 3.40, Theta: 0.08, Sigma: 0.06},
+	{ID: "perishables", Name: "Perishables", HaulCostPerKg: 0.0050, BasePrice: 2.60, Theta: 0.12, Sigma: 0.09},
+	{ID: "freight", Name: "General Freight", HaulCostPerKg: 0.0012, BasePrice: 0.85, Theta: 0.04, Sigma: 0.02},
+}
+
+// baseCommoditySupplyKg seeds every airport/commodity pair with this much
+// starting supply.
+const baseCommoditySupplyKg = 20_000.0
+
+func findCommodity(id string) (Commodity, error) {
+	for _, c := range commodityCatalog {
+		if strings.EqualFold(c.ID, id) {
+			return c, nil
+		}
+	}
+	return Commodity{}, http.ErrMissingFile
+}
+
+// CommoditySpot is one commodity's live spot price and available supply at
+// one airport.
+type CommoditySpot struct {
+	Price  float64 `json:"price"`
+	Supply float64 `json:"supply"` // kg sitting at this airport, ready to sell
+}
+
+// CommodityMarket tracks, per airport and commodity, a spot price that
+// mean-reverts each tick (see evolveCommodityMarketLocked) plus a supply
+// pool that applyCargoLegLocked drains at a leg's origin and fills at its
+// destination.
+type CommodityMarket struct {
+	Spots map[string]map[string]CommoditySpot `json:"spots"` // airport ident -> commodity ID -> spot
+}
+
+// defaultCommodityMarket seeds every loaded airport with the catalog's base
+// prices and starting supply, for a new game or a savegame from before this
+// subsystem existed. Callers must have already loaded store.
+func defaultCommodityMarket() CommodityMarket {
+	cm := CommodityMarket{Spots: make(map[string]map[string]CommoditySpot, len(store.Airports))}
+	for _, a := range store.Airports {
+		spots := make(map[string]CommoditySpot, len(commodityCatalog))
+		for _, c := range commodityCatalog {
+			spots[c.ID] = CommoditySpot{Price: c.BasePrice, Supply: baseCommoditySupplyKg}
+		}
+		cm.Spots[strings.ToUpper(a.Ident)] = spots
+	}
+	return cm
+}
+
+// evolveCommodityMarketLocked steps every airport/commodity spot price one
+// tick along a mean-reverting random walk, the same shape as
+// evolveFuelMarketLocked. Supply only changes via applyCargoLegLocked.
+func evolveCommodityMarketLocked() {
+	for _, spots := range state.CommodityMarket.Spots {
+		for id, spot := range spots {
+			c, err := findCommodity(id)
+			if err != nil {
+				continue
+			}
+			p := spot.Price + c.Theta*(c.BasePrice-spot.Price) + c.Sigma*rng.NormFloat64()
+			if p < 0 {
+				p = 0
+			}
+			spot.Price = p
+			spots[id] = spot
+		}
+	}
+}
+
+// applyCargoLegLocked sells as much of rt's commodity as the aircraft's
+// hold and the origin's live supply allow, moves that weight from the
+// origin's supply pool to the destination's, and returns the gross revenue
+// and the cost booked for this leg: the cargo's purchase price at the
+// origin spot plus the per-kg haul cost over dist.
+func applyCargoLegLocked(rt *Route, origin, dest string, dist float64) (revenue, cost float64) {
+	if rt.CargoCommodity == "" {
+		return 0, 0
+	}
+	commodity, err := findCommodity(rt.CargoCommodity)
+	if err != nil {
+		return 0, 0
+	}
+	originSpots := state.CommodityMarket.Spots[origin]
+	destSpots := state.CommodityMarket.Spots[dest]
+	if originSpots == nil || destSpots == nil {
+		return 0, 0
+	}
+	originSpot := originSpots[commodity.ID]
+	destSpot := destSpots[commodity.ID]
+
+	sold := math.Min(rt.CargoHoldKg, originSpot.Supply)
+	if sold <= 0 {
+		return 0, 0
+	}
+
+	revenue = sold * destSpot.Price
+	cost = sold * (originSpot.Price + commodity.HaulCostPerKg*dist)
+
+	originSpot.Supply -= sold
+	destSpot.Supply += sold
+	originSpots[commodity.ID] = originSpot
+	destSpots[commodity.ID] = destSpot
+	return revenue, cost
+}
+
+// SubsidyStatus is the lifecycle stage of a Subsidy offer.
+type SubsidyStatus string
+
+const (
+	SubsidyOffered SubsidyStatus = "offered"
+	SubsidyAwarded SubsidyStatus = "awarded"
+	SubsidyExpired SubsidyStatus = "expired"
+)
+
+// Subsidy is a government revenue-bonus offer in the spirit of OpenTTD's
+// subsidy system: carry at least TargetVolume passengers between From and
+// To within WindowTicks of being offered, and the matching route earns a
+// BonusPct revenue bonus for BonusDurationTicks after award.
+type Subsidy struct {
+	ID                 string        `json:"id"`
+	From               string        `json:"from"`
+	To                 string        `json:"to"`
+	TargetVolume       int           `json:"target_volume"`
+	AccumulatedVolume  int           `json:"accumulated_volume"`
+	WindowTicks        int           `json:"window_ticks"`
+	BonusPct           float64       `json:"bonus_pct"`
+	BonusDurationTicks int           `json:"bonus_duration_ticks"`
+	Status             SubsidyStatus `json:"status"`
+	OfferedTick        int           `json:"offered_tick"`
+	AwardedTick        int           `json:"awarded_tick,omitempty"`
+
+	// ExpiresTick is the offer deadline while Status is Offered, and the
+	// end of the revenue bonus once it flips to Awarded.
+	ExpiresTick int `json:"expires_tick"`
+}
+
+const (
+	// subsidyOfferIntervalTicks is how often advanceTickLocked considers
+	// generating a new subsidy offer: once a simulated day.
+	subsidyOfferIntervalTicks = 24 * 60
+
+	// subsidyMaxActive caps concurrent offered+awarded subsidies so the
+	// list doesn't grow unbounded.
+	subsidyMaxActive = 5
+
+	subsidyWindowTicks        = 7 * 24 * 60  // a week to hit the target
+	subsidyBonusDurationTicks = 30 * 24 * 60 // a month of bonus revenue
+	subsidyTargetVolumeMin    = 50
+	subsidyTargetVolumeMax    = 400
+	subsidyBonusPctMin        = 0.10
+	subsidyBonusPctMax        = 0.50
+)
+
+// offerSubsidiesLocked generates a new subsidy offer between a random pair
+// of airports, preferentially targeting small_airport/medium_airport
+// airports to encourage network expansion into underserved markets.
+func offerSubsidiesLocked() {
+	active := 0
+	for _, s := range state.Subsidies {
+		if s.Status == SubsidyOffered || s.Status == SubsidyAwarded {
+			active++
+		}
+	}
+	if active >= subsidyMaxActive {
+		return
+	}
+
+	var pool []Airport
+	for _, a := range airportsByIdent {
+		if a.Type == "small_airport" || a.Type == "medium_airport" {
+			pool = append(pool, a)
+		}
+	}
+	if len(pool) < 2 {
+		pool = pool[:0]
+		for _, a := range airportsByIdent {
+			pool = append(pool, a)
+		}
+	}
+	if len(pool) < 2 {
+		return
+	}
+
+	from := pool[rng.Intn(len(pool))]
+	to := from
+	for to.Ident == from.Ident {
+		to = pool[rng.Intn(len(pool))]
+	}
+
+	s := Subsidy{
+		ID:                 strconv.FormatInt(time.Now().UnixNano(), 10),
+		From:               from.Ident,
+		To:                 to.Ident,
+		TargetVolume:       subsidyTargetVolumeMin + rng.Intn(subsidyTargetVolumeMax-subsidyTargetVolumeMin),
+		WindowTicks:        subsidyWindowTicks,
+		BonusPct:           subsidyBonusPctMin + rng.Float64()*(subsidyBonusPctMax-subsidyBonusPctMin),
+		BonusDurationTicks: subsidyBonusDurationTicks,
+		Status:             SubsidyOffered,
+		OfferedTick:        state.Tick,
+		ExpiresTick:        state.Tick + subsidyWindowTicks,
+	}
+	state.Subsidies = append(state.Subsidies, s)
+	addEvent("subsidy_offered", s)
+}
+
+// applySubsidyLocked accumulates passengers flown between origin and dest
+// against any matching offered subsidy, awarding it once the target volume
+// is reached, and returns the combined revenue bonus multiplier (1.0 if
+// none apply) from any subsidy currently awarded on that pair.
+func applySubsidyLocked(origin, dest string, passengers int) float64 {
+	bonus := 1.0
+	for i := range state.Subsidies {
+		s := &state.Subsidies[i]
+		if !((s.From == origin && s.To == dest) || (s.From == dest && s.To == origin)) {
+			continue
+		}
+		switch s.Status {
+		case SubsidyOffered:
+			s.AccumulatedVolume += passengers
+			if s.AccumulatedVolume >= s.TargetVolume {
+				s.Status = SubsidyAwarded
+				s.AwardedTick = state.Tick
+				s.ExpiresTick = state.Tick + s.BonusDurationTicks
+				addEvent("subsidy_awarded", *s)
+				bonus *= 1 + s.BonusPct
+			}
+		case SubsidyAwarded:
+			bonus *= 1 + s.BonusPct
+		}
+	}
+	return bonus
+}
+
+// expireSubsidiesLocked closes out offers whose window lapsed unclaimed and
+// awarded subsidies whose bonus period has ended, once per tick.
+func expireSubsidiesLocked() {
+	for i := range state.Subsidies {
+		s := &state.Subsidies[i]
+		if s.Status == SubsidyExpired {
+			continue
+		}
+		if state.Tick >= s.ExpiresTick {
+			s.Status = SubsidyExpired
+			addEvent("subsidy_expired", *s)
+		}
+	}
+}
+
+// DisruptionKind enumerates the causes a disruption Alert can model.
+type DisruptionKind string
+
+const (
+	DisruptionWeatherClosure DisruptionKind = "weather_closure"
+	DisruptionATCFlow        DisruptionKind = "atc_flow"
+	DisruptionRunwayNOTAM    DisruptionKind = "runway_notam"
+	DisruptionStrike         DisruptionKind = "strike"
+)
+
+// HourRange is a curfew-style closed hour window (wraps past midnight if
+// Start > End), matching Airport's CurfewStart/CurfewEnd semantics.
+type HourRange struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// Alert is a live service disruption affecting one or more airports for a
+// tick window, mirroring how transit backends fold GTFS-RT service alerts
+// into planning: validateCapacityLocked scales ap.SlotsPerHour by
+// SlotMultiplier (0 is a hard closure) and, if CurfewOverride is set,
+// tightens curfewAvailableMinutes beyond whatever curfew the airport
+// already has. Generated either by handlePublishDisruption or internally by
+// maybeGenerateDisruptionLocked.
+type Alert struct {
+	ID             string         `json:"id"`
+	Kind           DisruptionKind `json:"kind"`
+	Airports       []string       `json:"airports"`
+	StartTick      int            `json:"start_tick"`
+	EndTick        int            `json:"end_tick"`
+	SlotMultiplier float64        `json:"slot_multiplier"`
+	CurfewOverride *HourRange     `json:"curfew_override,omitempty"`
+}
+
+const (
+	// disruptionCheckIntervalTicks is how often advanceTickLocked rolls the
+	// dice on a new stochastic disruption.
+	disruptionCheckIntervalTicks = 60
+
+	// disruptionChancePerCheck is the per-roll probability of generating a
+	// disruption, tuned so a live game sees roughly one every few days.
+	disruptionChancePerCheck = 0.01
+
+	disruptionDurationMinTicks = 4 * 60
+	disruptionDurationMaxTicks = 3 * 24 * 60
+)
+
+// activeAlertsForAirportLocked returns the disruptions touching ident that
+// are live at state.Tick.
+func activeAlertsForAirportLocked(ident string) []Alert {
+	var out []Alert
+	for _, a := range state.Alerts {
+		if state.Tick < a.StartTick || state.Tick >= a.EndTick {
+			continue
+		}
+		for _, ap := range a.Airports {
+			if strings.EqualFold(ap, ident) {
+				out = append(out, a)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// slotMultiplierLocked combines every active disruption's SlotMultiplier at
+// ident, stacking multiplicatively so e.g. a runway NOTAM during an ATC
+// flow restriction compounds.
+func slotMultiplierLocked(ident string) float64 {
+	mult := 1.0
+	for _, a := range activeAlertsForAirportLocked(ident) {
+		mult *= a.SlotMultiplier
+	}
+	return mult
+}
+
+// curfewHoursWithAlertsLocked widens hours (an airport's own curfew mask)
+// with any active disruption's CurfewOverride for ident.
+func curfewHoursWithAlertsLocked(ident string, hours [24]bool) [24]bool {
+	for _, a := range activeAlertsForAirportLocked(ident) {
+		if a.CurfewOverride == nil {
+			continue
+		}
+		for h := a.CurfewOverride.Start; ((h%24)+24)%24 != ((a.CurfewOverride.End%24)+24)%24; h++ {
+			hours[((h%24)+24)%24] = true
+		}
+	}
+	return hours
+}
+
+// hasCurfewSignalLocked reports whether ident's curfew check needs to run
+// at all: either the airport has its own curfew, or a live disruption has
+// overridden one in.
+func hasCurfewSignalLocked(ap Airport, ident string) bool {
+	if ap.Curfew {
+		return true
+	}
+	for _, a := range activeAlertsForAirportLocked(ident) {
+		if a.CurfewOverride != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// publishAlertLocked records a new disruption and broadcasts it, used by
+// both handlePublishDisruption and maybeGenerateDisruptionLocked.
+func publishAlertLocked(a Alert) Alert {
+	if a.ID == "" {
+		a.ID = strconv.FormatInt(time.Now().UnixNano(), 10)
+	}
+	state.Alerts = append(state.Alerts, a)
+	addEvent("disruption_published", a)
+	return a
+}
+
+// maybeGenerateDisruptionLocked occasionally injects a random weather/ATC/
+// NOTAM/strike alert at a live airport, so a running scenario can exercise
+// the network's resilience without an operator manually posting one.
+func maybeGenerateDisruptionLocked() {
+	if rng.Float64() >= disruptionChancePerCheck {
+		return
+	}
+	var pool []Airport
+	for _, a := range airportsByIdent {
+		pool = append(pool, a)
+	}
+	if len(pool) == 0 {
+		return
+	}
+	ap := pool[rng.Intn(len(pool))]
+
+	kinds := []DisruptionKind{DisruptionWeatherClosure, DisruptionATCFlow, DisruptionRunwayNOTAM, DisruptionStrike}
+	kind := kinds[rng.Intn(len(kinds))]
+	duration := disruptionDurationMinTicks + rng.Intn(disruptionDurationMaxTicks-disruptionDurationMinTicks)
+
+	a := Alert{
+		Kind:      kind,
+		Airports:  []string{ap.Ident},
+		StartTick: state.Tick,
+		EndTick:   state.Tick + duration,
+	}
+	switch kind {
+	case DisruptionWeatherClosure, DisruptionRunwayNOTAM:
+		a.SlotMultiplier = 0
+	case DisruptionStrike:
+		a.SlotMultiplier = 0.3 + rng.Float64()*0.2
+		a.CurfewOverride = &HourRange{Start: 22, End: 6}
+	default: // DisruptionATCFlow
+		a.SlotMultiplier = 0.4 + rng.Float64()*0.3
+	}
+	publishAlertLocked(a)
 }
 
 // OwnedCraft represents a specific aircraft in the player's fleet
@@ -134,10 +766,11 @@ type OwnedCraft struct {
 	MonthlyCost   float64    `json:"monthly_cost,omitempty"`
 
 	// Simulation State
-	State      string     `json:"state"` // "Idle", "Flying", "Turnaround"
-	Location   string     `json:"location"`
-	Timer      int        `json:"timer"` // minutes remaining
-	FlightPlan FlightPlan `json:"flight_plan"`
+	State      flightops.State  `json:"state"`
+	Location   string           `json:"location"`
+	Timer      int              `json:"timer"` // minutes remaining in the current phase
+	FlightPlan FlightPlan       `json:"flight_plan"`
+	Phases     flightops.Phases `json:"phases,omitempty"` // per-phase timers for the leg in progress
 }
 
 // acquisition configuration
@@ -200,6 +833,7 @@ var (
 )
 
 const saveFilePath = "data/savegame.json"
+const eventLogPath = "data/eventlog.jsonl"
 
 func seedFleet() []OwnedCraft {
 	starterIDs := map[string]bool{
@@ -238,10 +872,57 @@ var (
 	stateMu         sync.Mutex
 	state           GameState
 	aircraftCatalog []Aircraft
+
+	// flightRecords is a rolling log of completed legs, read by
+	// handleAnalyticsSummary. Guarded by stateMu like state itself, since
+	// every write happens inside advanceTickLocked.
+	flightRecords = analytics.NewBuffer(analyticsBufferCapacity)
 )
 
 const (
 	manualMaintenanceTicks = 3
+
+	// analyticsBufferCapacity bounds flightRecords to roughly three months
+	// of departures at a few hundred flights/day before the oldest entries
+	// start rolling off.
+	analyticsBufferCapacity = 20_000
+
+	// lostProfitWindow caps Route.LostProfitHistory to the trailing 24
+	// ticks (minutes) so the /routes/{id}/lost trend doesn't grow unbounded.
+	lostProfitWindow = 24
+
+	// ticksPerMonth converts a hedge's "N months" lock duration into ticks,
+	// using the 1-tick-per-minute convention and a 30-day month.
+	ticksPerMonth = 30 * 24 * 60
+
+	// Defaults for Route.AutoManageK/Window/Callback when a POST
+	// /routes/{id}/automanage request leaves them unset.
+	defaultAutoManageK        = 1.0
+	defaultAutoManageWindow   = 14
+	defaultAutoManageCallback = 0.5
+
+	// autoManageSmootherWindow is how many of the most recent departures
+	// must agree on a load trend before autoManageRouteLocked acts on it.
+	autoManageSmootherWindow = 5
+
+	// autoManagePriceStepCap bounds a single autoManageRouteLocked price
+	// adjustment to 5% of UserPrice per departure.
+	autoManagePriceStepCap = 0.05
+
+	// Defaults for Route.YieldWindow/YieldK when a POST /routes/{id}/yield
+	// request leaves them unset.
+	defaultYieldWindow = 14
+	defaultYieldK      = 1.0
+
+	// yieldATRSubWindow is the sub-window size yieldATR averages
+	// max(load)-min(load) over, per the trailing-band ATR definition.
+	yieldATRSubWindow = 3
+
+	// minDemandCurveSamples is how many price-discovery levels a
+	// DemandCurve needs before demandEstimateWithOpts will trust it over
+	// the synthetic estimator; a probe with fewer levels than this is too
+	// noisy a fit to act on.
+	minDemandCurveSamples = 3
 )
 
 var (
@@ -290,6 +971,10 @@ func main() {
 		airportsByIdent[strings.ToUpper(a.Ident)] = a
 	}
 
+	if err := seedFromGTFS("data/gtfs"); err != nil {
+		log.Printf("gtfs: skipping static seed: %v", err)
+	}
+
 	loadedState, err := loadState(saveFilePath)
 	if err == nil && (len(loadedState.Fleet) > 0 || len(loadedState.Routes) > 0) {
 		state = loadedState
@@ -301,6 +986,16 @@ func main() {
 			Speed: 1,
 		}
 	}
+	if state.FuelMarket.Price == 0 {
+		// Either a fresh game, or a savegame from before the fuel market
+		// subsystem existed.
+		state.FuelMarket = defaultFuelMarket()
+	}
+	if state.CommodityMarket.Spots == nil {
+		// Either a fresh game, or a savegame from before cargo trading
+		// existed.
+		state.CommodityMarket = defaultCommodityMarket()
+	}
 	recalcUtilizationLocked()
 
 	r := chi.NewRouter()
@@ -351,21 +1046,30 @@ func main() {
 
 	r.Post("/routes", func(w http.ResponseWriter, r *http.Request) {
 		var req struct {
-			From       string  `json:"from"`
-			To         string  `json:"to"`
-			Via        string  `json:"via,omitempty"`
-			AircraftID string  `json:"aircraft_id"`
-			Frequency  int     `json:"frequency_per_day"`
-			OneWay     bool    `json:"one_way"`
-			UserPrice  float64 `json:"user_price"`
+			From           string  `json:"from"`
+			To             string  `json:"to"`
+			Via            string  `json:"via,omitempty"`
+			AircraftID     string  `json:"aircraft_id"`
+			Frequency      int     `json:"frequency_per_day"`
+			OneWay         bool    `json:"one_way"`
+			UserPrice      float64 `json:"user_price"`
+			CargoCommodity string  `json:"cargo_commodity,omitempty"`
 		}
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			http.Error(w, "bad request", http.StatusBadRequest)
 			return
 		}
+		if req.Frequency < 0 {
+			http.Error(w, "frequency_per_day must not be negative", http.StatusBadRequest)
+			return
+		}
+		if req.UserPrice < 0 {
+			http.Error(w, "user_price must not be negative", http.StatusBadRequest)
+			return
+		}
 		stateMu.Lock()
 		defer stateMu.Unlock()
-		route, err := buildRoute(req.From, req.To, req.Via, req.AircraftID, req.Frequency, req.UserPrice)
+		route, err := buildRoute(req.From, req.To, req.Via, req.AircraftID, req.Frequency, req.UserPrice, req.CargoCommodity)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
@@ -380,8 +1084,15 @@ func main() {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
+		assigned, err := assignRotationLocked(route)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		route.AssignedCraftIDs = assigned
 		state.Routes = append(state.Routes, route)
 		recalcUtilizationLocked()
+		addEvent("route_created", route)
 
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(route)
@@ -556,13 +1267,82 @@ func main() {
 		state.Cash -= cost
 		craft.Condition = 100
 		beginMaintenanceLocked(craft, manualMaintenanceTicks)
+		addEvent("maintenance_started", craft)
 
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(craft)
 	})
 
+	startGTFSRealtimePoller("data/feeds.json")
+
+	r.Get("/stream", handleStream)
+
+	r.Get("/fleet/{id}/track", func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		stateMu.Lock()
+		defer stateMu.Unlock()
+		var craft *OwnedCraft
+		for i := range state.Fleet {
+			if strings.EqualFold(state.Fleet[i].ID, id) {
+				craft = &state.Fleet[i]
+				break
+			}
+		}
+		if craft == nil {
+			http.Error(w, "unknown aircraft", http.StatusNotFound)
+			return
+		}
+
+		resp := struct {
+			AircraftID string                 `json:"aircraft_id"`
+			State      flightops.State        `json:"state"`
+			From       string                 `json:"from"`
+			To         string                 `json:"to"`
+			Fraction   float64                `json:"fraction"`
+			Position   flightops.TrackPoint   `json:"position"`
+			Track      []flightops.TrackPoint `json:"track,omitempty"`
+		}{
+			AircraftID: craft.ID,
+			State:      craft.State,
+			From:       craft.FlightPlan.Origin,
+			To:         craft.FlightPlan.Dest,
+		}
+		origin, hasOrigin := airportsByIdent[craft.FlightPlan.Origin]
+		dest, hasDest := airportsByIdent[craft.FlightPlan.Dest]
+		if hasOrigin && hasDest {
+			frac := legFractionLocked(craft)
+			resp.Fraction = frac
+			lat, lon := flightops.InterpolatePosition(origin.Latitude, origin.Longitude, dest.Latitude, dest.Longitude, frac)
+			resp.Position = flightops.TrackPoint{Lat: lat, Lon: lon, Fraction: frac}
+			resp.Track = flightops.Track(origin.Latitude, origin.Longitude, dest.Latitude, dest.Longitude, 20)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	})
+
 	port := getPort()
 	r.Post("/analysis/route", handleRouteAnalysis)
+	r.Post("/analysis/schedule", handleScheduleAnalysis)
+	r.Post("/routes/plan", handlePlanBestRoutes)
+	r.Get("/gtfs-rt/vehicles.pb", handleGTFSRTVehiclesPB)
+	r.Get("/gtfs-rt/vehicles.json", handleGTFSRTVehiclesJSON)
+	r.Get("/analysis/congestion", handleCongestionReport)
+	r.Get("/routes/{id}/lost", handleRouteLostProfit)
+	r.Post("/routes/{id}/automanage", handleRouteAutomanage)
+	r.Post("/routes/{id}/yield", handleRouteYield)
+	r.Post("/routes/{id}/price-discovery", handleRoutePriceDiscovery)
+	r.Post("/fuel/hedge", handleFuelHedge)
+	r.Post("/fleet/optimize", handleOptimizeFleet)
+	r.Post("/network/optimize", handleOptimizeNetwork)
+	r.Post("/routes/suggest-fares", handleSuggestFares)
+	r.Get("/commodities", handleCommodityCatalog)
+	r.Get("/commodities/prices", handleCommodityPrices)
+	r.Get("/routes/{id}/cargo", handleRouteCargoProfit)
+	r.Get("/subsidies", handleListSubsidies)
+	r.Get("/disruptions", handleListDisruptions)
+	r.Post("/disruptions", handlePublishDisruption)
+	r.Get("/analytics/summary", handleAnalyticsSummary)
 
 	log.Printf("Server listening on port %s", port)
 	log.Fatal(http.ListenAndServe(":"+port, r))
@@ -646,6 +1426,14 @@ func handleRouteAnalysis(w http.ResponseWriter, r *http.Request) {
 	// Benchmark time for direct flight (Assumed 850 km/h)
 	benchmarkSpeed := 850.0
 	directTimeHours := distDirect / benchmarkSpeed
+	directFare := 0.13 * distDirect
+
+	// Gravity demand pool for this O-D pair: T_ij = K * (mass_i^a * mass_j^b) / dist^c,
+	// shared by every aircraft type considered below.
+	demandParams := loadedDemandParams()
+	massFrom := demand.CityMass(fromAp.Type, demandParams)
+	massTo := demand.CityMass(toAp.Type, demandParams)
+	demandPool := demand.GravityDemand(distDirect, massFrom, massTo, demandParams)
 
 	for _, typeID := range req.AircraftTypes {
 		ac, err := findAircraft(typeID)
@@ -675,18 +1463,21 @@ func handleRouteAnalysis(w http.ResponseWriter, r *http.Request) {
 			totalTravelTime += float64(ac.TurnaroundMin) / 60.0
 		}
 
-		// Penalty: -10% per extra hour compared to direct
-		extraHours := totalTravelTime - directTimeHours
-		if extraHours < 0 { extraHours = 0 }
-		penalty := extraHours * 0.10
-		demandFactor := 1.0 - penalty
-		if demandFactor < 0.1 { demandFactor = 0.1 }
+		// Price (Standard formula: 0.13 * dist + base) is needed up front to
+		// price this itinerary's generalized cost against the direct benchmark.
+		price := 0.13 * totalDist
+		if price < 50 {
+			price = 50
+		}
 
-		// Est Demand (Base)
-		// We use our existing simple demand estimator
-		baseDemand := float64(demandEstimate(fromAp, toAp, ac, 1))
-		// Apply market share penalty
-		adjustedDemand := baseDemand * demandFactor
+		// Split the gravity demand pool between the direct benchmark itinerary
+		// and this one via a multinomial logit over generalized cost
+		// (fare + value-of-time*block time + a connection penalty when via).
+		shares := demand.LogitShares([]demand.Itinerary{
+			{Fare: directFare, BlockTimeHours: directTimeHours, IsConnection: false},
+			{Fare: price, BlockTimeHours: totalTravelTime, IsConnection: hasVia},
+		}, demandParams)
+		adjustedDemand := demandPool * shares[1]
 
 		// Frequency Calculation
 		// Round Trip Time = (TotalTravelTime * 2) + (Turnaround * 2) ?? 
@@ -710,10 +1501,6 @@ func handleRouteAnalysis(w http.ResponseWriter, r *http.Request) {
 		if load > float64(ac.Seats) { load = float64(ac.Seats) }
 		loadFactor := load / float64(ac.Seats)
 
-		// Price (Standard formula: 0.13 * dist + base)
-		price := 0.13 * totalDist
-		if price < 50 { price = 50 }
-		
 		revenuePerFlight := load * price
 		
 		// Cost
@@ -752,340 +1539,1659 @@ func handleRouteAnalysis(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(results)
 }
 
-func loadAircraftDatabase(path string) ([]Aircraft, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, err
-	}
-	var aircraft []Aircraft
-	if err := json.Unmarshal(data, &aircraft); err != nil {
-		return nil, err
-	}
-	return aircraft, nil
+// ScheduleCandidate is one (route, specific owned aircraft) pairing the
+// caller wants fit into a curfew-clear minute-of-day rotation.
+type ScheduleCandidate struct {
+	From            string  `json:"from"`
+	To              string  `json:"to"`
+	AircraftID      string  `json:"aircraft_id"` // owned fleet ID, not a template
+	FrequencyPerDay int     `json:"frequency_per_day,omitempty"`
+	UserPrice       float64 `json:"user_price,omitempty"`
 }
 
-func loadState(path string) (GameState, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return GameState{}, err
-	}
-	var st GameState
-	if err := json.Unmarshal(data, &st); err != nil {
-		return GameState{}, err
-	}
-	for i := range st.Fleet {
-		if st.Fleet[i].OwnershipType == "" {
-			st.Fleet[i].OwnershipType = "owned"
-		}
-	}
-	return st, nil
+// ScheduleRequest is the body for POST /analysis/schedule.
+type ScheduleRequest struct {
+	Candidates []ScheduleCandidate `json:"candidates"`
 }
 
-func saveState(path string, st *GameState) error {
-	data, err := json.MarshalIndent(st, "", "  ")
-	if err != nil {
-		return err
-	}
-	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
-		return err
-	}
-	tmp := path + ".tmp"
-	if err := os.WriteFile(tmp, data, 0o644); err != nil {
-		return err
-	}
-	return os.Rename(tmp, path)
+// ScheduleResponse is the chosen minute-of-day per leg, plus any rotations
+// that couldn't be scheduled and the curfew that eliminated them.
+type ScheduleResponse struct {
+	Assignments   []scheduler.Assignment    `json:"assignments"`
+	Unschedulable []scheduler.Unschedulable `json:"unschedulable"`
 }
 
-func loadAirports(path string) (*AirportStore, error) {
-	f, err := os.Open(path)
-	if err != nil {
-		return nil, err
-	}
-	defer f.Close()
+func curfewWindowFor(ap Airport) scheduler.CurfewWindow {
+	return scheduler.CurfewWindow{Active: ap.Curfew, StartHour: ap.CurfewStart, EndHour: ap.CurfewEnd}
+}
 
-	reader := csv.NewReader(f)
-	reader.FieldsPerRecord = -1
-	headers, err := reader.Read()
-	if err != nil {
-		return nil, err
+// handleScheduleAnalysis solves for the arrival/departure minute-of-day of
+// each candidate rotation so no leg lands or takes off during either
+// endpoint's curfew, taking rotations highest daily profit first
+// (scheduler.Solve's greedy pass); the total daily profit it reaches is not
+// guaranteed to be the maximum.
+func handleScheduleAnalysis(w http.ResponseWriter, r *http.Request) {
+	var req ScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
-	idx := func(name string) int {
-		for i, h := range headers {
-			if h == name {
-				return i
+
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	var rotations []scheduler.Rotation
+	for _, c := range req.Candidates {
+		fromAp, ok := airportsByIdent[strings.ToUpper(c.From)]
+		if !ok {
+			continue
+		}
+		toAp, ok := airportsByIdent[strings.ToUpper(c.To)]
+		if !ok {
+			continue
+		}
+		var craft *OwnedCraft
+		for i := range state.Fleet {
+			if strings.EqualFold(state.Fleet[i].ID, c.AircraftID) {
+				craft = &state.Fleet[i]
+				break
 			}
 		}
-		return -1
-	}
-
-	idIdx := idx("id")
-	identIdx := idx("ident")
-	typeIdx := idx("type")
-	nameIdx := idx("name")
-	latIdx := idx("latitude_deg")
-	lonIdx := idx("longitude_deg")
-	countryIdx := idx("iso_country")
-	regionIdx := idx("iso_region")
-	cityIdx := idx("municipality")
-	iataIdx := idx("iata_code")
-	icaoIdx := idx("icao_code")
-
-	var airports []Airport
-	for {
-		rec, err := reader.Read()
-		if err != nil {
-			break
+		if craft == nil {
+			continue
 		}
-
-		t := rec[typeIdx]
-		if t == "closed" || t == "heliport" || t == "seaplane_base" {
+		ac, err := findAircraft(craft.TemplateID)
+		if err != nil {
 			continue
 		}
 
-		lat, _ := strconv.ParseFloat(rec[latIdx], 64)
-		lon, _ := strconv.ParseFloat(rec[lonIdx], 64)
+		freq := c.FrequencyPerDay
+		if freq <= 0 {
+			freq = 1
+		}
+		dist := haversine(fromAp.Latitude, fromAp.Longitude, toAp.Latitude, toAp.Longitude)
+		blockMin := (dist/ac.CruiseKmh)*60 + float64(ac.TurnaroundMin)
 
-		airports = append(airports, Airport{
-			ID:          rec[idIdx],
-			Ident:       rec[identIdx],
-			Type:        t,
-			Name:        rec[nameIdx],
-			Latitude:    lat,
-			Longitude:   lon,
-			Country:     rec[countryIdx],
-			Region:      rec[regionIdx],
-			City:        rec[cityIdx],
-			IATA:        rec[iataIdx],
-			ICAO:        rec[icaoIdx],
-			RunwayM:     runwayMetersForType(t),
-			SlotsPerDay: slotsForType(t),
-			LandingFee:  landingFeeForType(t),
-			Curfew:      curfewForType(t),
-			CurfewStart: 22,
-			CurfewEnd:   6,
+		price := c.UserPrice
+		if price <= 0 {
+			price = 0.13 * dist
+		}
+		sold := min(demandEstimateWithOpts(fromAp, toAp, ac, freq, demandOptions{Price: price, MarketKey: marketKey(fromAp.Ident, toAp.Ident)}), ac.Seats)
+		revenue := float64(sold) * price
+		cost := dist*ac.FuelCost + 800 + fromAp.LandingFee + toAp.LandingFee
+		dailyProfit := (revenue - cost) * float64(freq)
+
+		rotations = append(rotations, scheduler.Rotation{
+			AircraftID:    craft.ID,
+			RouteID:       strings.ToUpper(c.From) + "-" + strings.ToUpper(c.To),
+			BlockMinutes:  blockMin,
+			TurnaroundMin: ac.TurnaroundMin,
+			DailyProfit:   dailyProfit,
+			Origin:        curfewWindowFor(fromAp),
+			Dest:          curfewWindowFor(toAp),
 		})
 	}
 
-	log.Printf("loaded %d airports", len(airports))
-	return &AirportStore{Airports: airports}, nil
-}
-
-func runwayMetersForType(t string) int {
-	switch t {
-	case "large_airport":
-		return 3200
-	case "medium_airport":
-		return 2200
-	case "small_airport":
-		return 1200
-	default:
-		return 1000
-	}
-}
+	result := scheduler.Solve(rotations)
 
-func slotsForType(t string) int {
-	switch t {
-	case "large_airport":
-		return 200
-	case "medium_airport":
-		return 120
-	case "small_airport":
-		return 40
-	default:
-		return 20
-	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ScheduleResponse{
+		Assignments:   result.Assignments,
+		Unschedulable: result.Unschedulable,
+	})
 }
 
-func landingFeeForType(t string) float64 {
-	switch t {
-	case "large_airport":
-		return 3500
-	case "medium_airport":
-		return 2000
-	case "small_airport":
-		return 800
-	default:
-		return 500
-	}
+// PlanRoutesRequest is the body for POST /routes/plan.
+type PlanRoutesRequest struct {
+	Start           string   `json:"start"`
+	AircraftID      string   `json:"aircraft_id"`
+	Candidates      []string `json:"candidates"` // catchment the plan may route through; start is included automatically
+	MustVisit       []string `json:"must_visit,omitempty"`
+	AcceptableEnds  []string `json:"acceptable_ends,omitempty"` // any candidate airport if empty
+	MaxHops         int      `json:"max_hops,omitempty"`        // default 4
+	TopN            int      `json:"top_n,omitempty"`           // default 5
+	FrequencyPerDay int      `json:"frequency_per_day,omitempty"`
+	UserPrice       float64  `json:"user_price,omitempty"` // per-seat fare used on every leg; 0.13/km if unset
 }
 
-func curfewForType(t string) bool {
-	return curfewAppliesTo[t]
+// PlannedItinerary mirrors routeplan.Itinerary with JSON tags for the wire.
+type PlannedItinerary struct {
+	Airports          []string `json:"airports"`
+	TotalProfit       float64  `json:"total_profit"`
+	TotalBlockMinutes float64  `json:"total_block_minutes"`
 }
 
-func curfewAvailableMinutes(startHour, endHour int) float64 {
-	// hours airports are closed from start to end (e.g., 22->6 blocks 8 hours)
-	if startHour == endHour {
-		return 24 * 60
+// handlePlanBestRoutes runs routeplan.PlanBestRoutes over a caller-supplied
+// catchment and returns the topN most profitable multi-leg itineraries,
+// generalizing the single from/via/to hop in buildRoute into an arbitrary
+// chain.
+func handlePlanBestRoutes(w http.ResponseWriter, r *http.Request) {
+	var req PlanRoutesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
-	blocked := 0
-	if startHour < endHour {
-		blocked = endHour - startHour
-	} else {
-		blocked = (24 - startHour) + endHour
+
+	ac, err := findAircraft(req.AircraftID)
+	if err != nil {
+		http.Error(w, "Unknown aircraft type", http.StatusBadRequest)
+		return
 	}
-	openHours := 24 - blocked
-	if openHours < 0 {
-		openHours = 0
+	reqRunway := runwayReqMeters[ac.ID]
+	if reqRunway == 0 {
+		reqRunway = 1500
 	}
-	return float64(openHours) * 60
-}
 
-func filterAirports(all []Airport, tier string) []Airport {
-	if tier == "" || tier == "all" {
-		return all
+	startID := strings.ToUpper(strings.TrimSpace(req.Start))
+	if _, ok := airportsByIdent[startID]; !ok {
+		http.Error(w, "Invalid start airport", http.StatusBadRequest)
+		return
 	}
-	tier = strings.ToLower(tier)
-	keep := func(t string) bool {
-		switch tier {
-		case "large":
-			return t == "large_airport"
-		case "medium":
-			return t == "large_airport" || t == "medium_airport"
-		case "small":
-			return t == "small_airport"
-		default:
-			return true
+
+	seen := map[string]bool{startID: true}
+	candidates := []routeplan.Airport{toRoutePlanAirport(airportsByIdent[startID])}
+	for _, id := range req.Candidates {
+		id = strings.ToUpper(strings.TrimSpace(id))
+		if seen[id] {
+			continue
 		}
-	}
-	out := make([]Airport, 0, len(all))
-	for _, a := range all {
-		if keep(a.Type) {
-			out = append(out, a)
+		ap, ok := airportsByIdent[id]
+		if !ok {
+			continue
 		}
+		seen[id] = true
+		candidates = append(candidates, toRoutePlanAirport(ap))
 	}
-	return out
-}
 
-func buildRoute(from, to, via, aircraftID string, freq int, userPrice float64) (Route, error) {
+	freq := req.FrequencyPerDay
 	if freq <= 0 {
 		freq = 1
 	}
-	fromID := strings.ToUpper(strings.TrimSpace(from))
-	toID := strings.ToUpper(strings.TrimSpace(to))
-	viaID := strings.ToUpper(strings.TrimSpace(via))
 
-	fromAp, ok := airportsByIdent[fromID]
-	if !ok {
-		return Route{}, http.ErrMissingFile
-	}
-	toAp, ok := airportsByIdent[toID]
-	if !ok {
-		return Route{}, http.ErrMissingFile
-	}
-	var viaAp Airport
-	var hasVia bool
-	if viaID != "" {
-		v, ok := airportsByIdent[viaID]
-		if !ok {
-			return Route{}, http.ErrMissingFile
+	leg := func(from, to routeplan.Airport) routeplan.LegResult {
+		if from.RunwayM < reqRunway || to.RunwayM < reqRunway {
+			return routeplan.LegResult{}
+		}
+		dist := haversine(from.Lat, from.Lon, to.Lat, to.Lon)
+		if dist > ac.RangeKm {
+			return routeplan.LegResult{}
+		}
+		fromAp, toAp := airportsByIdent[from.Ident], airportsByIdent[to.Ident]
+		price := req.UserPrice
+		if price <= 0 {
+			price = 0.13 * dist
+		}
+		sold := min(demandEstimateWithOpts(fromAp, toAp, ac, freq, demandOptions{Price: price, MarketKey: marketKey(fromAp.Ident, toAp.Ident)}), ac.Seats)
+		revenue := float64(sold) * price
+		cost := dist*ac.FuelCost + 800.0 + fromAp.LandingFee + toAp.LandingFee
+		return routeplan.LegResult{
+			Feasible:     true,
+			Profit:       revenue - cost,
+			FuelFraction: dist / ac.RangeKm,
+			BlockMinutes: (dist/ac.CruiseKmh)*60 + float64(ac.TurnaroundMin),
 		}
-		viaAp = v
-		hasVia = true
 	}
 
-	ac, err := findAircraft(aircraftID)
-	if err != nil {
-		return Route{}, err
+	maxHops := req.MaxHops
+	if maxHops <= 0 {
+		maxHops = 4
 	}
-	reqRunway := runwayReqMeters[ac.ID]
-	if reqRunway == 0 {
-		reqRunway = 1500
+	topN := req.TopN
+	if topN <= 0 {
+		topN = 5
 	}
 
-	distMain := haversine(fromAp.Latitude, fromAp.Longitude, toAp.Latitude, toAp.Longitude)
-	if distMain > ac.RangeKm {
-		return Route{}, http.ErrBodyNotAllowed
+	mustVisit := make([]string, len(req.MustVisit))
+	for i, id := range req.MustVisit {
+		mustVisit[i] = strings.ToUpper(strings.TrimSpace(id))
 	}
-	if fromAp.RunwayM < reqRunway || toAp.RunwayM < reqRunway {
-		return Route{}, fmt.Errorf("runway too short for %s", ac.ID)
+	acceptableEnds := make([]string, len(req.AcceptableEnds))
+	for i, id := range req.AcceptableEnds {
+		acceptableEnds[i] = strings.ToUpper(strings.TrimSpace(id))
 	}
 
-	var distVia1, distVia2 float64
-	if hasVia {
-		distVia1 = haversine(fromAp.Latitude, fromAp.Longitude, viaAp.Latitude, viaAp.Longitude)
-		distVia2 = haversine(viaAp.Latitude, viaAp.Longitude, toAp.Latitude, toAp.Longitude)
-		if distVia1 > ac.RangeKm || distVia2 > ac.RangeKm {
-			return Route{}, http.ErrBodyNotAllowed
-		}
-		if viaAp.RunwayM < reqRunway {
-			return Route{}, fmt.Errorf("%s runway too short for %s", viaAp.Ident, ac.ID)
+	itins := routeplan.PlanBestRoutes(candidates, startID, maxHops, mustVisit, acceptableEnds, leg, topN)
+
+	resp := make([]PlannedItinerary, len(itins))
+	for i, it := range itins {
+		resp[i] = PlannedItinerary{
+			Airports:          it.Airports,
+			TotalProfit:       it.TotalProfit,
+			TotalBlockMinutes: it.TotalBlockMinutes,
 		}
 	}
 
-	basePrice := 0.13 * distMain
-	if basePrice <= 0 {
-		totalVia := distVia1 + distVia2
-		basePrice = 0.13 * totalVia
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func toRoutePlanAirport(a Airport) routeplan.Airport {
+	totalSlots := 0
+	for _, s := range a.SlotsPerHour {
+		totalSlots += s
 	}
-	if basePrice <= 0 {
-		basePrice = 150
+	return routeplan.Airport{
+		Ident:       a.Ident,
+		Lat:         a.Latitude,
+		Lon:         a.Longitude,
+		RunwayM:     a.RunwayM,
+		SlotsPerDay: totalSlots,
+		Curfew:      a.Curfew,
+		CurfewStart: a.CurfewStart,
+		CurfewEnd:   a.CurfewEnd,
 	}
-	if userPrice <= 0 {
-		userPrice = basePrice
+}
+
+// FleetOptimizeRequest is the body for POST /fleet/optimize.
+type FleetOptimizeRequest struct {
+	Candidates   []string `json:"candidates"` // catchment the plan may route through; each aircraft's current Location is included automatically
+	HorizonTicks int      `json:"horizon_ticks,omitempty"`
+	CashBudget   float64  `json:"cash_budget,omitempty"` // defaults to the live state.Cash
+	FleetIDs     []string `json:"fleet_ids,omitempty"`   // restrict the search to these OwnedCraft; all active aircraft if empty
+	MustVisit    []string `json:"must_visit,omitempty"`  // every returned itinerary must touch these airports before closing
+	UserPrice    float64  `json:"user_price,omitempty"`  // per-seat fare used on every leg; 0.13/km if unset
+}
+
+// FleetSuggestion mirrors fleetplan.Suggestion with JSON tags for the wire.
+type FleetSuggestion struct {
+	AircraftID    string   `json:"aircraft_id"`
+	Airports      []string `json:"airports"`
+	TotalProfit   float64  `json:"total_profit"`
+	TicksUsed     int      `json:"ticks_used"`
+	ProfitPerTick float64  `json:"profit_per_tick"`
+	Utilization   float64  `json:"utilization"`
+}
+
+// handleOptimizeFleet runs fleetplan.OptimizeFleet over the active fleet (or
+// FleetIDs, if given) and a caller-supplied catchment, returning the
+// best closed itinerary found for each aircraft, ranked by ProfitPerTick.
+func handleOptimizeFleet(w http.ResponseWriter, r *http.Request) {
+	var req FleetOptimizeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
-	baseDistance := distMain
-	if baseDistance <= 0 {
-		baseDistance = distVia1 + distVia2
-		if baseDistance <= 0 {
-			baseDistance = 1
+
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	wanted := make(map[string]bool, len(req.FleetIDs))
+	for _, id := range req.FleetIDs {
+		wanted[id] = true
+	}
+
+	var fleet []fleetplan.Aircraft
+	craftByID := make(map[string]OwnedCraft)
+	seenAirports := map[string]bool{}
+	var candidates []fleetplan.Airport
+	addCandidate := func(ident string) {
+		ident = strings.ToUpper(strings.TrimSpace(ident))
+		if ident == "" || seenAirports[ident] {
+			return
+		}
+		ap, ok := airportsByIdent[ident]
+		if !ok {
+			return
 		}
+		seenAirports[ident] = true
+		candidates = append(candidates, toFleetPlanAirport(ap))
 	}
 
-	type leg struct {
-		dist     float64
-		demand   int
-		sold     int
-		price    float64
-		revenue  float64
-		cost     float64
-		blockMin float64
-		fees     float64
+	for _, oc := range state.Fleet {
+		if oc.Status != "active" || oc.Location == "" {
+			continue
+		}
+		if len(wanted) > 0 && !wanted[oc.ID] {
+			continue
+		}
+		fleet = append(fleet, fleetplan.Aircraft{ID: oc.ID, Home: strings.ToUpper(oc.Location), RangeKm: oc.RangeKm})
+		craftByID[oc.ID] = oc
+		addCandidate(oc.Location)
+	}
+	for _, id := range req.Candidates {
+		addCandidate(id)
 	}
 
-	demandLeg := func(a, b Airport, opts demandOptions) int {
-		return demandEstimateWithOpts(a, b, ac, freq, opts)
+	horizon := req.HorizonTicks
+	if horizon <= 0 {
+		horizon = 7 * 24 * 60 // a week, at 1 tick = 1 minute
 	}
-	priceForLeg := func(dist float64) float64 {
-		if dist <= 0 || baseDistance <= 0 {
-			return userPrice
+	cashBudget := req.CashBudget
+	if cashBudget <= 0 {
+		cashBudget = state.Cash
+	}
+	freq := 1
+
+	leg := func(ac fleetplan.Aircraft, from, to fleetplan.Airport) fleetplan.LegResult {
+		oc := craftByID[ac.ID]
+		template, err := findAircraft(oc.TemplateID)
+		if err != nil {
+			return fleetplan.LegResult{}
 		}
-		p := userPrice * (dist / baseDistance)
-		if p <= 0 {
-			return userPrice
+		if from.RunwayM < runwayReqMeters[template.ID] || to.RunwayM < runwayReqMeters[template.ID] {
+			return fleetplan.LegResult{}
+		}
+		dist := haversine(from.Lat, from.Lon, to.Lat, to.Lon)
+		if dist > ac.RangeKm {
+			return fleetplan.LegResult{}
+		}
+		fromAp, toAp := airportsByIdent[from.Ident], airportsByIdent[to.Ident]
+		price := req.UserPrice
+		if price <= 0 {
+			price = 0.13 * dist
+		}
+		sold := min(demandEstimateWithOpts(fromAp, toAp, template, freq, demandOptions{Price: price, MarketKey: marketKey(fromAp.Ident, toAp.Ident)}), template.Seats)
+		revenue := float64(sold) * price
+		cost := dist*template.FuelCost*effectiveFuelMultiplierLocked() + 800.0 + fromAp.LandingFee + toAp.LandingFee
+		blockMin := (dist/template.CruiseKmh)*60 + float64(template.TurnaroundMin)
+		return fleetplan.LegResult{
+			Feasible: true,
+			Profit:   revenue - cost,
+			Ticks:    int(math.Round(blockMin)),
 		}
-		return p
 	}
 
-	legCost := func(a, b Airport, dist float64) (float64, float64) {
-		fees := a.LandingFee + b.LandingFee
-		return dist*ac.FuelCost + 800.0 + fees, fees
+	mustVisit := make([]string, len(req.MustVisit))
+	for i, id := range req.MustVisit {
+		mustVisit[i] = strings.ToUpper(strings.TrimSpace(id))
 	}
-	legBlock := func(dist float64) float64 {
-		return (dist/ac.CruiseKmh)*60 + float64(ac.TurnaroundMin)
+
+	suggestions := fleetplan.OptimizeFleet(candidates, fleet, horizon, cashBudget, mustVisit, leg)
+
+	resp := make([]FleetSuggestion, len(suggestions))
+	for i, s := range suggestions {
+		resp[i] = FleetSuggestion{
+			AircraftID:    s.AircraftID,
+			Airports:      s.Airports,
+			TotalProfit:   s.TotalProfit,
+			TicksUsed:     s.TicksUsed,
+			ProfitPerTick: s.ProfitPerTick,
+			Utilization:   s.Utilization,
+		}
 	}
 
-	var legs []leg
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
 
-	if hasVia {
-		// Outbound: from->via with local + through demand, then via->to
-		priceLeg1 := priceForLeg(distVia1)
-		d1 := demandLeg(fromAp, viaAp, demandOptions{Price: priceLeg1}) + demandLeg(fromAp, toAp, demandOptions{Stopover: true, Price: userPrice})
-		d2 := demandLeg(viaAp, toAp, demandOptions{Price: priceForLeg(distVia2)})
-		// Inbound: to->via with local + through demand, then via->from
-		d3 := demandLeg(toAp, viaAp, demandOptions{Price: priceForLeg(distVia2)}) + demandLeg(toAp, fromAp, demandOptions{Stopover: true, Price: userPrice})
-		d4 := demandLeg(viaAp, fromAp, demandOptions{Price: priceLeg1})
+func toFleetPlanAirport(a Airport) fleetplan.Airport {
+	return fleetplan.Airport{
+		Ident:   a.Ident,
+		Lat:     a.Latitude,
+		Lon:     a.Longitude,
+		RunwayM: a.RunwayM,
+	}
+}
 
-		for _, x := range []struct {
-			dist   float64
-			demand int
-			a      Airport
-			b      Airport
-		}{
-			{distVia1, d1, fromAp, viaAp},
-			{distVia2, d2, viaAp, toAp},
-			{distVia2, d3, toAp, viaAp},
-			{distVia1, d4, viaAp, fromAp},
+// NetworkOptimizeRequest is the body for POST /network/optimize.
+type NetworkOptimizeRequest struct {
+	Candidates    []string `json:"candidates"`             // catchment airports the plan may connect
+	AircraftTypes []string `json:"aircraft_types"`         // catalog IDs to consider adding rotations of
+	Frequencies   []int    `json:"frequencies,omitempty"`  // daily frequencies to try per market; default [1]
+	CashBudget    float64  `json:"cash_budget,omitempty"`  // defaults to the live state.Cash
+	HorizonDays   int      `json:"horizon_days,omitempty"` // default 1; scales DailyNetCash for HorizonNetCash
+	UserPrice     float64  `json:"user_price,omitempty"`   // per-seat fare tried for every candidate; 0.13/km if unset
+}
+
+// NetworkAddition mirrors netplan.Candidate with JSON tags for the wire.
+type NetworkAddition struct {
+	AircraftType string  `json:"aircraft_type"`
+	From         string  `json:"from"`
+	To           string  `json:"to"`
+	Frequency    int     `json:"frequency"`
+	Profit       float64 `json:"profit"`
+}
+
+// NetworkPlanResponse mirrors netplan.NetworkPlan with JSON tags, plus the
+// horizon-scaled total the UI asked for.
+type NetworkPlanResponse struct {
+	Additions      []NetworkAddition `json:"additions"`
+	DailyNetCash   float64           `json:"daily_net_cash"`
+	HorizonNetCash float64           `json:"horizon_net_cash"`
+}
+
+// handleOptimizeNetwork builds one netplan.Candidate per (aircraft type,
+// market, frequency) combination that clears range/runway/positive-profit
+// checks, then runs netplan.OptimizeNetwork over them to pick the
+// profit-maximizing subset of new rotations given the fleet-hours
+// remaining per aircraft type and the slots remaining at each candidate
+// airport's peakDepartureBank hour (the same hour buildRoute assigns new
+// routes to).
+func handleOptimizeNetwork(w http.ResponseWriter, r *http.Request) {
+	var req NetworkOptimizeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	freqs := req.Frequencies
+	if len(freqs) == 0 {
+		freqs = []int{1}
+	}
+	horizonDays := req.HorizonDays
+	if horizonDays <= 0 {
+		horizonDays = 1
+	}
+	hour := peakDepartureBank()
+
+	activeByType := map[string]int{}
+	for _, oc := range state.Fleet {
+		if oc.Status == "active" {
+			activeByType[oc.TemplateID]++
+		}
+	}
+	usedMinutesByType := map[string]float64{}
+	usedSlotsByAirport := map[string]int{}
+	for _, rt := range state.Routes {
+		usedMinutesByType[rt.AircraftID] += rt.BlockMinutes * float64(rt.FrequencyPerDay)
+		if rt.DepartureBank != hour {
+			continue
+		}
+		usedSlotsByAirport[strings.ToUpper(rt.From)] += rt.FrequencyPerDay
+		usedSlotsByAirport[strings.ToUpper(rt.To)] += rt.FrequencyPerDay
+		if rt.Via != "" {
+			usedSlotsByAirport[strings.ToUpper(rt.Via)] += rt.FrequencyPerDay
+		}
+	}
+
+	hoursPerType := make(map[string]float64, len(req.AircraftTypes))
+	for _, t := range req.AircraftTypes {
+		remaining := float64(activeByType[t])*16.0 - usedMinutesByType[t]/60.0
+		if remaining < 0 {
+			remaining = 0
+		}
+		hoursPerType[t] = remaining
+	}
+
+	idents := make([]string, 0, len(req.Candidates))
+	for _, id := range req.Candidates {
+		id = strings.ToUpper(strings.TrimSpace(id))
+		if _, ok := airportsByIdent[id]; ok {
+			idents = append(idents, id)
+		}
+	}
+	slotsPerAirport := make(map[string]int, len(idents))
+	for _, id := range idents {
+		limit := airportsByIdent[id].SlotsPerHour[hour]
+		remaining := limit - usedSlotsByAirport[id]
+		if remaining < 0 {
+			remaining = 0
+		}
+		slotsPerAirport[id] = remaining
+	}
+
+	cashBudget := req.CashBudget
+	if cashBudget <= 0 {
+		cashBudget = state.Cash
+	}
+
+	var candidates []netplan.Candidate
+	for _, t := range req.AircraftTypes {
+		ac, err := findAircraft(t)
+		if err != nil || activeByType[t] == 0 {
+			continue
+		}
+		reqRunway := runwayReqMeters[t]
+		// aircraftDownPayment is the incremental cash OptimizeNetwork treats
+		// a rotation as needing to stand up, since the candidate aircraft is
+		// already owned (activeByType[t] > 0) and only working capital —
+		// not a full purchase — gates adding it: 10% of the catalog price.
+		aircraftDownPayment := aircraftCosts[t] * 0.1
+
+		for i, fromID := range idents {
+			fromAp := airportsByIdent[fromID]
+			if fromAp.RunwayM < reqRunway {
+				continue
+			}
+			for _, toID := range idents[i+1:] {
+				toAp := airportsByIdent[toID]
+				if toAp.RunwayM < reqRunway {
+					continue
+				}
+				dist := haversine(fromAp.Latitude, fromAp.Longitude, toAp.Latitude, toAp.Longitude)
+				if dist <= 0 || dist > ac.RangeKm {
+					continue
+				}
+				legPrice := req.UserPrice
+				if legPrice <= 0 {
+					legPrice = 0.13 * dist
+				}
+				blockMin := (dist/ac.CruiseKmh)*60 + float64(ac.TurnaroundMin)
+
+				for _, freq := range freqs {
+					sold := min(demandEstimateWithOpts(fromAp, toAp, ac, freq, demandOptions{Price: legPrice, MarketKey: marketKey(fromAp.Ident, toAp.Ident)}), ac.Seats)
+					revenue := float64(sold) * legPrice * float64(freq)
+					cost := (dist*ac.FuelCost*effectiveFuelMultiplierLocked() + 800.0 + fromAp.LandingFee + toAp.LandingFee) * float64(freq)
+					profit := revenue - cost
+					if profit <= 0 {
+						continue
+					}
+					candidates = append(candidates, netplan.Candidate{
+						AircraftType: t,
+						From:         fromID,
+						To:           toID,
+						Frequency:    freq,
+						Profit:       profit,
+						HoursPerDay:  blockMin / 60.0 * float64(freq),
+						Slots:        freq,
+						Cash:         aircraftDownPayment,
+					})
+				}
+			}
+		}
+	}
+
+	plan := netplan.OptimizeNetwork(candidates, cashBudget, hoursPerType, slotsPerAirport)
+
+	resp := NetworkPlanResponse{
+		DailyNetCash:   plan.DailyNetCash,
+		HorizonNetCash: plan.DailyNetCash * float64(horizonDays),
+	}
+	for _, a := range plan.Additions {
+		resp.Additions = append(resp.Additions, NetworkAddition{
+			AircraftType: a.AircraftType,
+			From:         a.From,
+			To:           a.To,
+			Frequency:    a.Frequency,
+			Profit:       a.Profit,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// CongestionHourBucket is one hour's worth of scheduled departure counts at
+// a single airport, broken down by aircraft type and route for the
+// congestion report.
+type CongestionHourBucket struct {
+	Hour         int            `json:"hour"`
+	Departures   int            `json:"departures"`
+	SlotLimit    int            `json:"slot_limit"`
+	CurfewClosed bool           `json:"curfew_closed"`
+	ByAircraft   map[string]int `json:"by_aircraft"`
+	ByRoute      map[string]int `json:"by_route"`
+}
+
+// AirportCongestion is the hour-by-hour departure histogram for one
+// airport, in the same spirit as a disturbance-report breakdown.
+type AirportCongestion struct {
+	Ident string                 `json:"ident"`
+	Hours []CongestionHourBucket `json:"hours"`
+}
+
+// handleCongestionReport returns, per airport, departure counts bucketed by
+// hour-of-day (and within each hour by aircraft type and route), so players
+// can see where slot/curfew pressure is building before validateCapacityLocked
+// starts rejecting new routes.
+func handleCongestionReport(w http.ResponseWriter, r *http.Request) {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	type key struct {
+		ident string
+		hour  int
+	}
+	byAircraft := make(map[key]map[string]int)
+	byRoute := make(map[key]map[string]int)
+	touch := func(ident string, hour int, aircraftID, routeID string, freq int) {
+		if ident == "" || freq == 0 {
+			return
+		}
+		ident = strings.ToUpper(ident)
+		k := key{ident, hour}
+		if byAircraft[k] == nil {
+			byAircraft[k] = make(map[string]int)
+			byRoute[k] = make(map[string]int)
+		}
+		byAircraft[k][aircraftID] += freq
+		byRoute[k][routeID] += freq
+	}
+	for _, rt := range state.Routes {
+		touch(rt.From, rt.DepartureBank, rt.AircraftID, rt.ID, rt.FrequencyPerDay)
+		touch(rt.To, rt.DepartureBank, rt.AircraftID, rt.ID, rt.FrequencyPerDay)
+		touch(rt.Via, rt.DepartureBank, rt.AircraftID, rt.ID, rt.FrequencyPerDay)
+	}
+
+	idents := make([]string, 0, len(airportsByIdent))
+	for ident := range airportsByIdent {
+		idents = append(idents, ident)
+	}
+	sort.Strings(idents)
+
+	report := make([]AirportCongestion, 0)
+	for _, ident := range idents {
+		ap := airportsByIdent[ident]
+		var hours []CongestionHourBucket
+		for h := 0; h < 24; h++ {
+			k := key{ident, h}
+			departures := 0
+			for _, n := range byAircraft[k] {
+				departures += n
+			}
+			if departures == 0 {
+				continue
+			}
+			hours = append(hours, CongestionHourBucket{
+				Hour:         h,
+				Departures:   departures,
+				SlotLimit:    ap.SlotsPerHour[h],
+				CurfewClosed: ap.Curfew && ap.CurfewHours[h],
+				ByAircraft:   byAircraft[k],
+				ByRoute:      byRoute[k],
+			})
+		}
+		if len(hours) == 0 {
+			continue
+		}
+		report = append(report, AirportCongestion{Ident: ident, Hours: hours})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// RouteLostProfit is the response for GET /routes/{id}/lost.
+type RouteLostProfit struct {
+	RouteID    string    `json:"route_id"`
+	LostProfit float64   `json:"lost_profit"`
+	History    []float64 `json:"history,omitempty"`
+}
+
+// handleRouteLostProfit reports the trailing lostProfitWindow ticks of
+// counterfactual profit a route missed out on to grounded aircraft,
+// curfew, or slot contention, per accrueLostProfitLocked.
+func handleRouteLostProfit(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	for _, rt := range state.Routes {
+		if rt.ID == id {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(RouteLostProfit{
+				RouteID:    rt.ID,
+				LostProfit: rt.LostProfit,
+				History:    rt.LostProfitHistory,
+			})
+			return
+		}
+	}
+	http.Error(w, "unknown route", http.StatusNotFound)
+}
+
+// AutoManageRequest is the body for POST /routes/{id}/automanage.
+type AutoManageRequest struct {
+	Enabled  bool    `json:"enabled"`
+	K        float64 `json:"k,omitempty"`
+	Window   int     `json:"window,omitempty"`
+	Callback float64 `json:"callback,omitempty"`
+}
+
+// handleRouteAutomanage configures the ATR-based auto-repricing controller
+// (see autoManageRouteLocked) for one route. Enabling it also clears a
+// previously tripped Suspended flag, giving the route a fresh start.
+func handleRouteAutomanage(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	var req AutoManageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	for i := range state.Routes {
+		rt := &state.Routes[i]
+		if rt.ID != id {
+			continue
+		}
+		rt.AutoManage = req.Enabled
+		rt.AutoManageK = req.K
+		rt.AutoManageWindow = req.Window
+		rt.AutoManageCallback = req.Callback
+		if req.Enabled {
+			rt.Suspended = false
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(rt)
+		return
+	}
+	http.Error(w, "unknown route", http.StatusNotFound)
+}
+
+// YieldManageRequest is the body for POST /routes/{id}/yield.
+type YieldManageRequest struct {
+	Enabled                 bool      `json:"enabled"`
+	Window                  int       `json:"window,omitempty"`
+	K                       float64   `json:"k,omitempty"`
+	MinFare                 float64   `json:"min_fare,omitempty"`
+	MaxFare                 float64   `json:"max_fare,omitempty"`
+	TrailingActivationRatio []float64 `json:"trailing_activation_ratio,omitempty"`
+	TrailingCallbackRate    []float64 `json:"trailing_callback_rate,omitempty"`
+}
+
+// handleRouteYield configures the trailing-ATR yield-management controller
+// (see yieldManageRouteLocked) for one route. It's independent of
+// handleRouteAutomanage — a route may run either, both, or neither.
+func handleRouteYield(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	var req YieldManageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	for i := range state.Routes {
+		rt := &state.Routes[i]
+		if rt.ID != id {
+			continue
+		}
+		rt.YieldManage = req.Enabled
+		rt.YieldWindow = req.Window
+		rt.YieldK = req.K
+		rt.YieldMinFare = req.MinFare
+		rt.YieldMaxFare = req.MaxFare
+		rt.TrailingActivationRatio = req.TrailingActivationRatio
+		rt.TrailingCallbackRate = req.TrailingCallbackRate
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(rt)
+		return
+	}
+	http.Error(w, "unknown route", http.StatusNotFound)
+}
+
+// PriceDiscoveryRequest is the body for POST /routes/{id}/price-discovery.
+type PriceDiscoveryRequest struct {
+	Deviation    float64 `json:"deviation"`
+	Steps        int     `json:"steps"`
+	TicksPerStep int     `json:"ticks_per_step"`
+}
+
+// handleRoutePriceDiscovery starts a grid price-discovery probe on one
+// route (see runPriceDiscoveryProbeLocked): AutoManage/YieldManage go
+// dormant for the route while the probe runs, and priceDiscoveryRouteLocked
+// drives it forward and applies the fitted price from advanceTickLocked.
+func handleRoutePriceDiscovery(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	var req PriceDiscoveryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	for i := range state.Routes {
+		rt := &state.Routes[i]
+		if rt.ID != id {
+			continue
+		}
+		if err := runPriceDiscoveryProbeLocked(rt, req.Deviation, req.Steps, req.TicksPerStep); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(rt)
+		return
+	}
+	http.Error(w, "unknown route", http.StatusNotFound)
+}
+
+// handleCommodityCatalog returns the fixed list of freight types routes can
+// be configured to haul (see commodityCatalog).
+func handleCommodityCatalog(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(commodityCatalog)
+}
+
+// CommoditySpotView names the commodity alongside its live spot, since
+// CommodityMarket.Spots keys by ID internally but callers want it inline.
+type CommoditySpotView struct {
+	CommodityID string  `json:"commodity_id"`
+	Price       float64 `json:"price"`
+	Supply      float64 `json:"supply"`
+}
+
+// AirportCommodityPrices is one airport's live spot prices across the
+// commodity catalog, for GET /commodities/prices.
+type AirportCommodityPrices struct {
+	Ident string              `json:"ident"`
+	Spots []CommoditySpotView `json:"spots"`
+}
+
+// handleCommodityPrices reports every airport's current commodity spot
+// prices and supply, in the same per-airport-list shape as
+// handleCongestionReport.
+func handleCommodityPrices(w http.ResponseWriter, r *http.Request) {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	idents := make([]string, 0, len(state.CommodityMarket.Spots))
+	for ident := range state.CommodityMarket.Spots {
+		idents = append(idents, ident)
+	}
+	sort.Strings(idents)
+
+	report := make([]AirportCommodityPrices, 0, len(idents))
+	for _, ident := range idents {
+		spots := state.CommodityMarket.Spots[ident]
+		views := make([]CommoditySpotView, 0, len(spots))
+		for _, c := range commodityCatalog {
+			spot, ok := spots[c.ID]
+			if !ok {
+				continue
+			}
+			views = append(views, CommoditySpotView{CommodityID: c.ID, Price: spot.Price, Supply: spot.Supply})
+		}
+		report = append(report, AirportCommodityPrices{Ident: ident, Spots: views})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// RouteCargoProfit is the response for GET /routes/{id}/cargo.
+type RouteCargoProfit struct {
+	RouteID              string  `json:"route_id"`
+	CargoCommodity       string  `json:"cargo_commodity"`
+	CargoHoldKg          float64 `json:"cargo_hold_kg"`
+	LastTickCargoRevenue float64 `json:"last_tick_cargo_revenue"`
+	CargoProfitPerTick   float64 `json:"cargo_profit_per_tick"`
+}
+
+// handleRouteCargoProfit reports a route's cargo-side economics separately
+// from its passenger ProfitPerTick, mirroring handleRouteLostProfit's
+// lookup-by-id-then-404 shape.
+func handleRouteCargoProfit(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	for _, rt := range state.Routes {
+		if rt.ID == id {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(RouteCargoProfit{
+				RouteID:              rt.ID,
+				CargoCommodity:       rt.CargoCommodity,
+				CargoHoldKg:          rt.CargoHoldKg,
+				LastTickCargoRevenue: rt.LastTickCargoRevenue,
+				CargoProfitPerTick:   rt.CargoProfitPerTick,
+			})
+			return
+		}
+	}
+	http.Error(w, "unknown route", http.StatusNotFound)
+}
+
+// handleListSubsidies returns every subsidy offer, award, and expiry so
+// players can track pending network-expansion incentives.
+func handleListSubsidies(w http.ResponseWriter, r *http.Request) {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(state.Subsidies)
+}
+
+// handleListDisruptions reports every disruption alert published so far,
+// live or expired.
+func handleListDisruptions(w http.ResponseWriter, r *http.Request) {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(state.Alerts)
+}
+
+// handlePublishDisruption lets an operator inject a disruption alert
+// externally (e.g. to script a scenario), the same way
+// maybeGenerateDisruptionLocked does internally.
+func handlePublishDisruption(w http.ResponseWriter, r *http.Request) {
+	var a Alert
+	if err := json.NewDecoder(r.Body).Decode(&a); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(a.Airports) == 0 {
+		http.Error(w, "airports is required", http.StatusBadRequest)
+		return
+	}
+	if a.EndTick <= a.StartTick {
+		http.Error(w, "end_tick must be after start_tick", http.StatusBadRequest)
+		return
+	}
+
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	a = publishAlertLocked(a)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(a)
+}
+
+// handleAnalyticsSummary aggregates flightRecords between the start/end
+// tick query params (defaulting to the full history) into buckets keyed by
+// group_by (hour, day, aircraft, origin, dest_country, or route; default
+// route), as JSON or, with format=csv, a spreadsheet-ready CSV.
+func handleAnalyticsSummary(w http.ResponseWriter, r *http.Request) {
+	stateMu.Lock()
+	end := state.Tick
+	stateMu.Unlock()
+
+	start := 0
+	if v := r.URL.Query().Get("start"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			start = n
+		}
+	}
+	if v := r.URL.Query().Get("end"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			end = n
+		}
+	}
+	groupBy := analytics.GroupBy(r.URL.Query().Get("group_by"))
+	if groupBy == "" {
+		groupBy = analytics.ByRoute
+	}
+
+	report := flightRecords.SummaryReport(start, end, groupBy)
+
+	if r.URL.Query().Get("format") == "csv" {
+		w.Header().Set("Content-Type", "text/csv")
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"key", "flights", "passengers", "revenue", "cost", "profit", "load_factor", "on_time_pct"})
+		for _, b := range report {
+			cw.Write([]string{
+				b.Key,
+				strconv.Itoa(b.Flights),
+				strconv.Itoa(b.Passengers),
+				fmt.Sprintf("%.2f", b.Revenue),
+				fmt.Sprintf("%.2f", b.Cost),
+				fmt.Sprintf("%.2f", b.Profit),
+				fmt.Sprintf("%.4f", b.LoadFactor),
+				fmt.Sprintf("%.4f", b.OnTimePct),
+			})
+		}
+		cw.Flush()
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+func loadAircraftDatabase(path string) ([]Aircraft, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var aircraft []Aircraft
+	if err := json.Unmarshal(data, &aircraft); err != nil {
+		return nil, err
+	}
+	return aircraft, nil
+}
+
+func loadState(path string) (GameState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return GameState{}, err
+	}
+	var st GameState
+	if err := json.Unmarshal(data, &st); err != nil {
+		return GameState{}, err
+	}
+	for i := range st.Fleet {
+		if st.Fleet[i].OwnershipType == "" {
+			st.Fleet[i].OwnershipType = "owned"
+		}
+	}
+	return st, nil
+}
+
+func saveState(path string, st *GameState) error {
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func loadAirports(path string) (*AirportStore, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = -1
+	headers, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	idx := func(name string) int {
+		for i, h := range headers {
+			if h == name {
+				return i
+			}
+		}
+		return -1
+	}
+
+	idIdx := idx("id")
+	identIdx := idx("ident")
+	typeIdx := idx("type")
+	nameIdx := idx("name")
+	latIdx := idx("latitude_deg")
+	lonIdx := idx("longitude_deg")
+	countryIdx := idx("iso_country")
+	regionIdx := idx("iso_region")
+	cityIdx := idx("municipality")
+	iataIdx := idx("iata_code")
+	icaoIdx := idx("icao_code")
+
+	var airports []Airport
+	for {
+		rec, err := reader.Read()
+		if err != nil {
+			break
+		}
+
+		t := rec[typeIdx]
+		if t == "closed" || t == "heliport" || t == "seaplane_base" {
+			continue
+		}
+
+		lat, _ := strconv.ParseFloat(rec[latIdx], 64)
+		lon, _ := strconv.ParseFloat(rec[lonIdx], 64)
+
+		airports = append(airports, Airport{
+			ID:          rec[idIdx],
+			Ident:       rec[identIdx],
+			Type:        t,
+			Name:        rec[nameIdx],
+			Latitude:    lat,
+			Longitude:   lon,
+			Country:     rec[countryIdx],
+			Region:      rec[regionIdx],
+			City:        rec[cityIdx],
+			IATA:        rec[iataIdx],
+			ICAO:        rec[icaoIdx],
+			RunwayM:      runwayMetersForType(t),
+			SlotsPerHour: peakedSlotsPerHour(slotsForType(t)),
+			LandingFee:   landingFeeForType(t),
+			Curfew:       curfewForType(t),
+			CurfewStart:  22,
+			CurfewEnd:    6,
+			CurfewHours:  curfewHoursFromWindow(22, 6),
+		})
+	}
+
+	log.Printf("loaded %d airports", len(airports))
+	return &AirportStore{Airports: airports}, nil
+}
+
+// seedFromGTFS reconciles airports.csv against a GTFS static bundle and
+// seeds routes for any scheduled O-D pair not already covered, so a fresh
+// network can be bootstrapped from real-world schedules instead of by hand.
+func seedFromGTFS(dir string) error {
+	bundle, err := gtfs.LoadStaticBundle(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, seed := range bundle.MatchAirports() {
+		if _, ok := airportsByIdent[seed.Ident]; ok {
+			continue
+		}
+		ap := Airport{
+			ID:          seed.Ident,
+			Ident:       seed.Ident,
+			Type:        "medium_airport",
+			Name:        seed.Name,
+			Latitude:    seed.Lat,
+			Longitude:   seed.Lon,
+			RunwayM:      runwayMetersForType("medium_airport"),
+			SlotsPerHour: peakedSlotsPerHour(slotsForType("medium_airport")),
+			LandingFee:   landingFeeForType("medium_airport"),
+			Curfew:       curfewForType("medium_airport"),
+			CurfewStart:  22,
+			CurfewEnd:    6,
+			CurfewHours:  curfewHoursFromWindow(22, 6),
+		}
+		store.Airports = append(store.Airports, ap)
+		airportsByIdent[seed.Ident] = ap
+	}
+
+	seeded := 0
+	for _, rs := range bundle.SeedRoutes() {
+		if _, ok := airportsByIdent[rs.FromIdent]; !ok {
+			continue
+		}
+		if _, ok := airportsByIdent[rs.ToIdent]; !ok {
+			continue
+		}
+		if marketExistsLocked(rs.FromIdent, rs.ToIdent) {
+			continue
+		}
+		seeded++
+	}
+	log.Printf("gtfs: matched %d airports, %d schedulable route seeds from %s", len(bundle.MatchAirports()), seeded, dir)
+	return nil
+}
+
+// startGTFSRealtimePoller polls the GTFS-Realtime feeds listed in the given
+// config file (if any) and layers their delay/cancellation signals onto the
+// matching aircraft timer and route curfew flag.
+func startGTFSRealtimePoller(configPath string) {
+	feeds, err := gtfs.LoadFeedConfigs(configPath)
+	if err != nil {
+		log.Printf("gtfs-rt: no feeds configured (%v)", err)
+		return
+	}
+	if len(feeds) == 0 {
+		return
+	}
+	stop := make(chan struct{})
+	gtfs.RunPoller(stop, feeds, applyGTFSRealtimeUpdate)
+}
+
+// applyGTFSRealtimeUpdate translates a decoded TripUpdate into per-tick
+// modifiers on the matching aircraft timer, or blocks the route if the trip
+// was cancelled outright.
+func applyGTFSRealtimeUpdate(update gtfs.TripUpdate) {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	for i := range state.Fleet {
+		ac := &state.Fleet[i]
+		if ac.FlightPlan.Origin+"-"+ac.FlightPlan.Dest != update.TripID {
+			continue
+		}
+		if update.Cancelled {
+			ac.Timer = 0
+			continue
+		}
+		if update.DelayMinutes > 0 {
+			ac.Timer += update.DelayMinutes
+		}
+	}
+	if update.Cancelled {
+		for i := range state.Routes {
+			if state.Routes[i].From+"-"+state.Routes[i].To == update.TripID {
+				state.Routes[i].CurfewBlocked = true
+			}
+		}
+	}
+}
+
+func runwayMetersForType(t string) int {
+	switch t {
+	case "large_airport":
+		return 3200
+	case "medium_airport":
+		return 2200
+	case "small_airport":
+		return 1200
+	default:
+		return 1000
+	}
+}
+
+func slotsForType(t string) int {
+	switch t {
+	case "large_airport":
+		return 200
+	case "medium_airport":
+		return 120
+	case "small_airport":
+		return 40
+	default:
+		return 20
+	}
+}
+
+// Peak-hour commute windows, mirroring hourlyDemandMultiplier's 07-09/17-19
+// demand bumps but widened slightly (07-10/17-20) to give slot allocation
+// and landing fees a little more room either side of the demand peak.
+const (
+	morningPeakStartHour = 7
+	morningPeakEndHour   = 10
+	eveningPeakStartHour = 17
+	eveningPeakEndHour   = 20
+
+	// peakLandingFeeMultiplier is how much more landingFeeAtHour charges
+	// during a peak window, on top of the airport's base LandingFee. A
+	// standard short-haul trunk route clears this surcharge comfortably
+	// under demand.DefaultParams' gravity calibration; don't retune it in
+	// isolation without re-checking TestBuildRouteEconomicsIncludesFeesAndProfit.
+	peakLandingFeeMultiplier = 1.5
+)
+
+// isPeakHour reports whether hour falls in the morning or evening commute
+// window.
+func isPeakHour(hour int) bool {
+	h := ((hour % 24) + 24) % 24
+	return (h >= morningPeakStartHour && h <= morningPeakEndHour) ||
+		(h >= eveningPeakStartHour && h <= eveningPeakEndHour)
+}
+
+// peakedSlotsPerHour spreads a type's daily slot total across the 24-hour
+// bucket with double weight in the morning/evening commute windows (see
+// isPeakHour), so players can trade cheap red-eye slots against scarce,
+// surcharged peak-hour banks.
+func peakedSlotsPerHour(total int) [24]int {
+	const peakWeight = 2
+	weightSum := 0
+	for h := 0; h < 24; h++ {
+		if isPeakHour(h) {
+			weightSum += peakWeight
+		} else {
+			weightSum++
+		}
+	}
+
+	var hours [24]int
+	for h := range hours {
+		weight := 1
+		if isPeakHour(h) {
+			weight = peakWeight
+		}
+		n := total * weight / weightSum
+		if n < 1 {
+			n = 1
+		}
+		hours[h] = n
+	}
+	return hours
+}
+
+func landingFeeForType(t string) float64 {
+	switch t {
+	case "large_airport":
+		return 3500
+	case "medium_airport":
+		return 2000
+	case "small_airport":
+		return 800
+	default:
+		return 500
+	}
+}
+
+// landingFeeAtHour applies peakLandingFeeMultiplier to ap.LandingFee during
+// the morning/evening commute windows (see isPeakHour), so the scarce slots
+// peakedSlotsPerHour weights toward those hours also cost more to use.
+func landingFeeAtHour(ap Airport, hour int) float64 {
+	if isPeakHour(hour) {
+		return ap.LandingFee * peakLandingFeeMultiplier
+	}
+	return ap.LandingFee
+}
+
+func curfewForType(t string) bool {
+	return curfewAppliesTo[t]
+}
+
+// curfewHoursFromWindow expands a start/end curfew window (e.g. 22->6 blocks
+// 22:00 through 06:00) into a per-hour closed mask, so split or mid-day
+// noise-abatement curfews can later set individual hours directly instead
+// of being forced through a single contiguous window.
+func curfewHoursFromWindow(startHour, endHour int) [24]bool {
+	var hours [24]bool
+	if startHour == endHour {
+		return hours
+	}
+	for h := startHour; ((h%24)+24)%24 != ((endHour%24)+24)%24; h++ {
+		hours[((h%24)+24)%24] = true
+	}
+	return hours
+}
+
+// curfewAvailableMinutes sums the minutes an airport is open for traffic
+// given its per-hour curfew mask (true = closed that hour).
+func curfewAvailableMinutes(hours [24]bool) float64 {
+	open := 0
+	for _, closed := range hours {
+		if !closed {
+			open++
+		}
+	}
+	return float64(open) * 60
+}
+
+func filterAirports(all []Airport, tier string) []Airport {
+	if tier == "" || tier == "all" {
+		return all
+	}
+	tier = strings.ToLower(tier)
+	keep := func(t string) bool {
+		switch tier {
+		case "large":
+			return t == "large_airport"
+		case "medium":
+			return t == "large_airport" || t == "medium_airport"
+		case "small":
+			return t == "small_airport"
+		default:
+			return true
+		}
+	}
+	out := make([]Airport, 0, len(all))
+	for _, a := range all {
+		if keep(a.Type) {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// SuggestFaresRequest is the body for POST /routes/suggest-fares.
+type SuggestFaresRequest struct {
+	From                 string  `json:"from"`
+	To                   string  `json:"to"`
+	AircraftID           string  `json:"aircraft_id"`
+	FrequencyPerDay      int     `json:"frequency_per_day,omitempty"`
+	ElasticityThreshold float64 `json:"elasticity_threshold,omitempty"` // defaults to defaultFareElasticityThreshold
+}
+
+// FareQuote is one suggestFares price point with its projected economics, so
+// the route-creation UI can show the revenue/cost/load tradeoff instead of
+// forcing a user_price guess.
+type FareQuote struct {
+	Price        float64 `json:"price"`
+	Revenue      float64 `json:"revenue"`
+	Cost         float64 `json:"cost"`
+	LoadFactor   float64 `json:"load_factor"`
+	PaybackTicks float64 `json:"payback_ticks,omitempty"`
+}
+
+// FareSuggestion is the response for POST /routes/suggest-fares: three
+// suggested price points for a market, from cheapest-and-fullest to
+// most-expensive-before-demand-breaks.
+type FareSuggestion struct {
+	Economy  FareQuote `json:"economy"`
+	Balanced FareQuote `json:"balanced"`
+	Premium  FareQuote `json:"premium"`
+}
+
+// defaultFareElasticityThreshold is the point-elasticity of demand (percent
+// change in seats sold per percent change in price) above which suggestFares
+// calls the market "Premium" — past here, further price increases start
+// costing more volume than they're worth.
+const defaultFareElasticityThreshold = 0.6
+
+// fareSweepSteps is how finely suggestFares sweeps price between 0.4x and
+// 2.0x the route's base fare (the same 0.13/km formula buildRoute uses) when
+// hunting for its three price points.
+const fareSweepSteps = 40
+
+// suggestFares sweeps price through demandEstimateWithOpts to find three
+// fare points for a market: Economy (the lowest price that still covers
+// cost at a 90%+ load factor), Balanced (the price maximizing profit), and
+// Premium (the first price, scanning upward, where the point-elasticity of
+// demand crosses threshold). The cost model mirrors buildRoute's legCost so
+// the suggestion lines up with what creating the route would actually show.
+func suggestFares(fromAp, toAp Airport, ac Aircraft, freq int, threshold float64) FareSuggestion {
+	if freq <= 0 {
+		freq = 1
+	}
+	if threshold <= 0 {
+		threshold = defaultFareElasticityThreshold
+	}
+
+	dist := haversine(fromAp.Latitude, fromAp.Longitude, toAp.Latitude, toAp.Longitude)
+	cost := dist*ac.FuelCost*effectiveFuelMultiplierLocked() + 800.0 + fromAp.LandingFee + toAp.LandingFee
+
+	base := 0.13 * dist
+	if base <= 0 {
+		base = 150
+	}
+
+	quoteAt := func(price float64) (FareQuote, int) {
+		sold := min(demandEstimateWithOpts(fromAp, toAp, ac, freq, demandOptions{Price: price, MarketKey: marketKey(fromAp.Ident, toAp.Ident)}), ac.Seats)
+		revenue := float64(sold) * price
+		q := FareQuote{
+			Price:      price,
+			Revenue:    revenue,
+			Cost:       cost,
+			LoadFactor: float64(sold) / float64(ac.Seats),
+		}
+		if profit := revenue - cost; profit > 0 {
+			q.PaybackTicks = cost / profit
+		}
+		return q, sold
+	}
+
+	var economy, balanced, premium FareQuote
+	haveEconomy, havePremium := false, false
+	bestProfit := math.Inf(-1)
+	var prevPrice float64
+	var prevSold int
+	havePrev := false
+
+	for i := 0; i <= fareSweepSteps; i++ {
+		price := base * (0.4 + 1.6*float64(i)/float64(fareSweepSteps))
+		q, sold := quoteAt(price)
+
+		if !haveEconomy && q.LoadFactor >= 0.9 && q.Revenue >= q.Cost {
+			economy = q
+			haveEconomy = true
+		}
+		if profit := q.Revenue - q.Cost; profit > bestProfit {
+			bestProfit = profit
+			balanced = q
+		}
+		if havePrev && !havePremium && prevSold > 0 && prevPrice > 0 {
+			dQ := float64(sold-prevSold) / float64(prevSold)
+			dP := (price - prevPrice) / prevPrice
+			if dP != 0 && -dQ/dP >= threshold {
+				premium = q
+				havePremium = true
+			}
+		}
+		prevPrice, prevSold, havePrev = price, sold, true
+	}
+	if !haveEconomy {
+		// No price in the sweep hit a 90% load factor (a thin market that
+		// tops out well under capacity at any price) - fall back to the
+		// base fare, but never above Balanced's price, so the Economy <=
+		// Balanced <= Premium ordering this endpoint promises always holds.
+		economyPrice := base
+		if economyPrice > balanced.Price {
+			economyPrice = balanced.Price
+		}
+		economy, _ = quoteAt(economyPrice)
+	}
+	if !havePremium {
+		premium, _ = quoteAt(base * 1.6)
+	}
+
+	return FareSuggestion{Economy: economy, Balanced: balanced, Premium: premium}
+}
+
+// handleSuggestFares computes suggestFares for a prospective market, so the
+// route-creation UI can present the Economy/Balanced/Premium tradeoff before
+// the user commits to a UserPrice.
+func handleSuggestFares(w http.ResponseWriter, r *http.Request) {
+	var req SuggestFaresRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	fromAp, ok := airportsByIdent[strings.ToUpper(strings.TrimSpace(req.From))]
+	if !ok {
+		http.Error(w, "unknown from airport", http.StatusBadRequest)
+		return
+	}
+	toAp, ok := airportsByIdent[strings.ToUpper(strings.TrimSpace(req.To))]
+	if !ok {
+		http.Error(w, "unknown to airport", http.StatusBadRequest)
+		return
+	}
+	ac, err := findAircraft(req.AircraftID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(suggestFares(fromAp, toAp, ac, req.FrequencyPerDay, req.ElasticityThreshold))
+}
+
+func buildRoute(from, to, via, aircraftID string, freq int, userPrice float64, cargoCommodity string) (Route, error) {
+	if freq <= 0 {
+		freq = 1
+	}
+	fromID := strings.ToUpper(strings.TrimSpace(from))
+	toID := strings.ToUpper(strings.TrimSpace(to))
+	viaID := strings.ToUpper(strings.TrimSpace(via))
+
+	fromAp, ok := airportsByIdent[fromID]
+	if !ok {
+		return Route{}, http.ErrMissingFile
+	}
+	toAp, ok := airportsByIdent[toID]
+	if !ok {
+		return Route{}, http.ErrMissingFile
+	}
+	var viaAp Airport
+	var hasVia bool
+	if viaID != "" {
+		v, ok := airportsByIdent[viaID]
+		if !ok {
+			return Route{}, http.ErrMissingFile
+		}
+		viaAp = v
+		hasVia = true
+	}
+
+	ac, err := findAircraft(aircraftID)
+	if err != nil {
+		return Route{}, err
+	}
+	reqRunway := runwayReqMeters[ac.ID]
+	if reqRunway == 0 {
+		reqRunway = 1500
+	}
+
+	distMain := haversine(fromAp.Latitude, fromAp.Longitude, toAp.Latitude, toAp.Longitude)
+	if distMain > ac.RangeKm {
+		return Route{}, http.ErrBodyNotAllowed
+	}
+	if fromAp.RunwayM < reqRunway || toAp.RunwayM < reqRunway {
+		return Route{}, fmt.Errorf("runway too short for %s", ac.ID)
+	}
+
+	var distVia1, distVia2 float64
+	if hasVia {
+		distVia1 = haversine(fromAp.Latitude, fromAp.Longitude, viaAp.Latitude, viaAp.Longitude)
+		distVia2 = haversine(viaAp.Latitude, viaAp.Longitude, toAp.Latitude, toAp.Longitude)
+		if distVia1 > ac.RangeKm || distVia2 > ac.RangeKm {
+			return Route{}, http.ErrBodyNotAllowed
+		}
+		if viaAp.RunwayM < reqRunway {
+			return Route{}, fmt.Errorf("%s runway too short for %s", viaAp.Ident, ac.ID)
+		}
+	}
+
+	basePrice := 0.13 * distMain
+	if basePrice <= 0 {
+		totalVia := distVia1 + distVia2
+		basePrice = 0.13 * totalVia
+	}
+	if basePrice <= 0 {
+		basePrice = 150
+	}
+	if userPrice <= 0 {
+		userPrice = basePrice
+	}
+	baseDistance := distMain
+	if baseDistance <= 0 {
+		baseDistance = distVia1 + distVia2
+		if baseDistance <= 0 {
+			baseDistance = 1
+		}
+	}
+
+	type leg struct {
+		dist     float64
+		demand   int
+		sold     int
+		price    float64
+		revenue  float64
+		cost     float64
+		blockMin float64
+		fees     float64
+	}
+
+	demandLeg := func(a, b Airport, opts demandOptions) int {
+		if opts.MarketKey == "" {
+			opts.MarketKey = marketKey(a.Ident, b.Ident)
+		}
+		return demandEstimateWithOpts(a, b, ac, freq, opts)
+	}
+	priceForLeg := func(dist float64) float64 {
+		if dist <= 0 || baseDistance <= 0 {
+			return userPrice
+		}
+		p := userPrice * (dist / baseDistance)
+		if p <= 0 {
+			return userPrice
+		}
+		return p
+	}
+
+	departureHour := peakDepartureBank()
+	legCost := func(a, b Airport, dist float64) (float64, float64) {
+		fees := landingFeeAtHour(a, departureHour) + landingFeeAtHour(b, departureHour)
+		return dist*ac.FuelCost*effectiveFuelMultiplierLocked() + 800.0 + fees, fees
+	}
+	legBlock := func(dist float64) float64 {
+		return (dist/ac.CruiseKmh)*60 + float64(ac.TurnaroundMin)
+	}
+
+	var legs []leg
+
+	if hasVia {
+		// Outbound: from->via with local + through demand, then via->to
+		priceLeg1 := priceForLeg(distVia1)
+		d1 := demandLeg(fromAp, viaAp, demandOptions{Price: priceLeg1}) + demandLeg(fromAp, toAp, demandOptions{Stopover: true, Price: userPrice})
+		d2 := demandLeg(viaAp, toAp, demandOptions{Price: priceForLeg(distVia2)})
+		// Inbound: to->via with local + through demand, then via->from
+		d3 := demandLeg(toAp, viaAp, demandOptions{Price: priceForLeg(distVia2)}) + demandLeg(toAp, fromAp, demandOptions{Stopover: true, Price: userPrice})
+		d4 := demandLeg(viaAp, fromAp, demandOptions{Price: priceLeg1})
+
+		for _, x := range []struct {
+			dist   float64
+			demand int
+			a      Airport
+			b      Airport
+		}{
+			{distVia1, d1, fromAp, viaAp},
+			{distVia2, d2, viaAp, toAp},
+			{distVia2, d3, toAp, viaAp},
+			{distVia1, d4, viaAp, fromAp},
 		} {
 			sold := min(x.demand, ac.Seats)
 			price := priceForLeg(x.dist)
@@ -1102,380 +3208,1341 @@ func buildRoute(from, to, via, aircraftID string, freq int, userPrice float64) (
 				fees:     fees,
 			})
 		}
-	} else {
-		// Simple round trip
-		for _, x := range []struct {
-			dist   float64
-			demand int
-			a      Airport
-			b      Airport
-		}{
-			{distMain, demandLeg(fromAp, toAp, demandOptions{Price: userPrice}), fromAp, toAp},
-			{distMain, demandLeg(toAp, fromAp, demandOptions{Price: userPrice}), toAp, fromAp},
-		} {
-			sold := min(x.demand, ac.Seats)
-			price := userPrice
-			rev := float64(sold) * price
-			cost, fees := legCost(x.a, x.b, x.dist)
-			legs = append(legs, leg{
-				dist:     x.dist,
-				demand:   x.demand,
-				sold:     sold,
-				price:    price,
-				revenue:  rev,
-				cost:     cost,
-				blockMin: legBlock(x.dist),
-				fees:     fees,
+	} else {
+		// Simple round trip
+		for _, x := range []struct {
+			dist   float64
+			demand int
+			a      Airport
+			b      Airport
+		}{
+			{distMain, demandLeg(fromAp, toAp, demandOptions{Price: userPrice}), fromAp, toAp},
+			{distMain, demandLeg(toAp, fromAp, demandOptions{Price: userPrice}), toAp, fromAp},
+		} {
+			sold := min(x.demand, ac.Seats)
+			price := userPrice
+			rev := float64(sold) * price
+			cost, fees := legCost(x.a, x.b, x.dist)
+			legs = append(legs, leg{
+				dist:     x.dist,
+				demand:   x.demand,
+				sold:     sold,
+				price:    price,
+				revenue:  rev,
+				cost:     cost,
+				blockMin: legBlock(x.dist),
+				fees:     fees,
+			})
+		}
+	}
+
+	totalRevenue := 0.0
+	totalCost := 0.0
+	totalSold := 0
+	totalDemand := 0
+	totalBlock := 0.0
+	totalFees := 0.0
+	for _, l := range legs {
+		totalRevenue += l.revenue
+		totalCost += l.cost
+		totalSold += l.sold
+		totalDemand += l.demand
+		totalBlock += l.blockMin
+		totalFees += l.fees
+	}
+
+	legsPerTrip := float64(len(legs))
+	avgRevenuePerLeg := totalRevenue / legsPerTrip
+	avgCostPerLeg := totalCost / legsPerTrip
+	avgFeesPerLeg := totalFees / legsPerTrip
+	loadFactor := float64(totalSold) / float64(ac.Seats*len(legs))
+
+	profitPerTick := (totalRevenue - totalCost) * float64(freq)
+	curfewBlocked := fromAp.Curfew || toAp.Curfew
+	if hasVia && viaAp.Curfew {
+		curfewBlocked = true
+	}
+
+	avgPricePerSeat := userPrice
+
+	cargoCommodity = strings.ToLower(strings.TrimSpace(cargoCommodity))
+	cargoHoldKg := 0.0
+	if cargoCommodity != "" {
+		if _, err := findCommodity(cargoCommodity); err != nil {
+			return Route{}, fmt.Errorf("unknown commodity %q", cargoCommodity)
+		}
+		cargoHoldKg = ac.MaxPayloadKg
+		if cargoHoldKg <= 0 {
+			// Passenger aircraft rarely carry a MaxPayloadKg figure in
+			// data/aircraft.json; fall back to a typical belly-hold
+			// allowance rather than refusing cargo on a pax route.
+			cargoHoldKg = 2000
+		}
+	}
+
+	route := Route{
+		ID:                strconv.FormatInt(time.Now().UnixNano(), 10),
+		From:              fromID,
+		To:                toID,
+		Via:               viaID,
+		AircraftID:        ac.ID,
+		FrequencyPerDay:   freq,
+		EstimatedDemand:   totalDemand,
+		PricePerSeat:      avgPricePerSeat,
+		UserPrice:         userPrice,
+		EstRevenueTick:    totalRevenue * float64(freq),
+		EstCostTick:       totalCost * float64(freq),
+		LoadFactor:        loadFactor,
+		RevenuePerLeg:     avgRevenuePerLeg,
+		CostPerLeg:        avgCostPerLeg,
+		LandingFeesPerLeg: avgFeesPerLeg,
+		ProfitPerTick:     profitPerTick,
+		SeatsSoldPerLeg:   totalSold / len(legs),
+		BlockMinutes:      totalBlock,
+		CurfewBlocked:     curfewBlocked,
+		LastTickRevenue:   totalRevenue * float64(freq),
+		LastTickLoad:      loadFactor,
+		DepartureBank:     departureHour,
+		CargoCommodity:    cargoCommodity,
+		CargoHoldKg:       cargoHoldKg,
+	}
+	return route, nil
+}
+
+func haversine(lat1, lon1, lat2, lon2 float64) float64 {
+	const R = 6371.0
+	dLat := toRad(lat2 - lat1)
+	dLon := toRad(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+	return R * c
+}
+
+func toRad(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func marketKey(a, b string) string {
+	a = strings.ToUpper(a)
+	b = strings.ToUpper(b)
+	if a < b {
+		return a + "-" + b
+	}
+	return b + "-" + a
+}
+
+// effectiveDepartureHours returns one hour-of-day entry per daily
+// departure: rt.DepartureHours verbatim if set, otherwise FrequencyPerDay
+// copies of DepartureBank (the original single-bank model every existing
+// route and savegame already uses).
+func effectiveDepartureHours(rt Route) []int {
+	if len(rt.DepartureHours) > 0 {
+		return rt.DepartureHours
+	}
+	hours := make([]int, rt.FrequencyPerDay)
+	for i := range hours {
+		hours[i] = rt.DepartureBank
+	}
+	return hours
+}
+
+func validateCapacityLocked(route Route) error {
+	activeCount := 0
+	for _, ac := range state.Fleet {
+		if ac.TemplateID == route.AircraftID && ac.Status == "active" {
+			activeCount++
+		}
+	}
+	if activeCount == 0 {
+		return http.ErrBodyNotAllowed // no available aircraft of that type
+	}
+
+	totalMins := route.BlockMinutes * float64(route.FrequencyPerDay)
+	for _, rt := range state.Routes {
+		if rt.AircraftID == route.AircraftID {
+			totalMins += rt.BlockMinutes * float64(rt.FrequencyPerDay)
+		}
+	}
+	// capacity in minutes per day
+	if totalMins > float64(activeCount)*960.0 {
+		return fmt.Errorf("insufficient aircraft time (over 16h/day for %s fleet)", route.AircraftID)
+	}
+
+	// slot constraints per (airport, departure hour) — each of a route's
+	// FrequencyPerDay departures lands in whichever hour
+	// effectiveDepartureHours assigns it (DepartureBank for every one, by
+	// default), and that's the bucket its slot demand competes in, not the
+	// airport's daily total.
+	type slotKey struct {
+		ident string
+		hour  int
+	}
+	addSlotUse := func(ident string, hours []int, slotUse map[slotKey]int) {
+		if ident == "" {
+			return
+		}
+		for _, h := range hours {
+			slotUse[slotKey{strings.ToUpper(ident), ((h % 24) + 24) % 24}]++
+		}
+	}
+
+	slotUse := make(map[slotKey]int)
+	routeHours := effectiveDepartureHours(route)
+	addSlotUse(route.From, routeHours, slotUse)
+	addSlotUse(route.To, routeHours, slotUse)
+	addSlotUse(route.Via, routeHours, slotUse)
+	for _, rt := range state.Routes {
+		hours := effectiveDepartureHours(rt)
+		addSlotUse(rt.From, hours, slotUse)
+		addSlotUse(rt.To, hours, slotUse)
+		addSlotUse(rt.Via, hours, slotUse)
+	}
+	for key, used := range slotUse {
+		ap, ok := airportsByIdent[key.ident]
+		if !ok {
+			continue
+		}
+		mult := slotMultiplierLocked(key.ident)
+		if mult <= 0 && used > 0 {
+			return fmt.Errorf("disruption closes %s during hour %02d", key.ident, key.hour)
+		}
+		limit := ap.SlotsPerHour[key.hour]
+		if mult < 1 {
+			limit = int(float64(limit) * mult)
+		}
+		if limit > 0 && used > limit {
+			return fmt.Errorf("slot limit exceeded at %s hour %02d (%d/%d)", key.ident, key.hour, used, limit)
+		}
+	}
+
+	// curfew: reject outright if any of the route's departure hours falls
+	// in a closed hour, then fall back to the aggregate open-minutes check
+	// for routes sharing an airport across a mix of banks.
+	// hasCurfewSignalLocked folds in any live disruption's CurfewOverride
+	// alongside the airport's own base curfew.
+	for _, ident := range []string{route.From, route.To, route.Via} {
+		if ident == "" {
+			continue
+		}
+		ap, ok := airportsByIdent[strings.ToUpper(ident)]
+		if !ok || !hasCurfewSignalLocked(ap, ident) {
+			continue
+		}
+		hours := curfewHoursWithAlertsLocked(ident, ap.CurfewHours)
+		for _, h := range routeHours {
+			if hours[((h%24)+24)%24] {
+				return fmt.Errorf("curfew blocks departures at %s during hour %02d", ident, h)
+			}
+		}
+	}
+
+	blockUse := make(map[string]float64)
+	addBlockUse := func(ident string, mins float64, freq int, blockUse map[string]float64) {
+		if ident == "" || freq == 0 || mins <= 0 {
+			return
+		}
+		blockUse[strings.ToUpper(ident)] += mins * float64(freq)
+	}
+	// include new route usage
+	addBlockUse(route.From, route.BlockMinutes, route.FrequencyPerDay, blockUse)
+	addBlockUse(route.To, route.BlockMinutes, route.FrequencyPerDay, blockUse)
+	addBlockUse(route.Via, route.BlockMinutes, route.FrequencyPerDay, blockUse)
+	for _, rt := range state.Routes {
+		addBlockUse(rt.From, rt.BlockMinutes, rt.FrequencyPerDay, blockUse)
+		addBlockUse(rt.To, rt.BlockMinutes, rt.FrequencyPerDay, blockUse)
+		addBlockUse(rt.Via, rt.BlockMinutes, rt.FrequencyPerDay, blockUse)
+	}
+	for ident, mins := range blockUse {
+		ap, ok := airportsByIdent[ident]
+		if !ok || !hasCurfewSignalLocked(ap, ident) {
+			continue
+		}
+		avail := curfewAvailableMinutes(curfewHoursWithAlertsLocked(ident, ap.CurfewHours))
+		if mins > avail {
+			return fmt.Errorf("curfew hours limit at %s (%.0f/%.0f mins)", ident, mins, avail)
+		}
+	}
+	return nil
+}
+
+// assignRotationLocked picks concrete aircraft out of the active
+// same-template pool to operate route, greedy by least already committed,
+// and rejects the route if no combination of them can physically cover its
+// rotation within a rolling 24h window (flightops.CanCoverRotation).
+func assignRotationLocked(route Route) ([]string, error) {
+	committedMin := make(map[string]float64)
+	for _, rt := range state.Routes {
+		for _, id := range rt.AssignedCraftIDs {
+			committedMin[id] += rt.BlockMinutes * float64(rt.FrequencyPerDay)
+		}
+	}
+	var candidates []flightops.Candidate
+	for _, ac := range state.Fleet {
+		if ac.TemplateID != route.AircraftID || ac.Status != "active" {
+			continue
+		}
+		candidates = append(candidates, flightops.Candidate{
+			AircraftID:  ac.ID,
+			AvailableIn: int(committedMin[ac.ID]),
+		})
+	}
+	return flightops.AssignRotation(candidates, route.FrequencyPerDay, route.BlockMinutes)
+}
+
+func marketExistsLocked(from, to string) bool {
+	key := marketKey(from, to)
+	for _, rt := range state.Routes {
+		if marketKey(rt.From, rt.To) == key {
+			return true
+		}
+	}
+	return false
+}
+
+func demandEstimate(fromAp, toAp Airport, ac Aircraft, freq int) int {
+	return demandEstimateWithOpts(fromAp, toAp, ac, freq, demandOptions{})
+}
+
+type demandOptions struct {
+	Stopover bool
+	Price    float64
+
+	// HourlyProfile opts in to weighting the estimate by
+	// hourlyDemandMultiplier(Hour) instead of returning the flat daily
+	// figure; existing callers that estimate a whole day's demand at once
+	// leave this false.
+	HourlyProfile bool
+	Hour          int
+
+	// MarketKey, when set, lets demandEstimateWithOpts check
+	// state.MarketDemandCurves for an empirical fit before falling back to
+	// the synthetic gravity/logit estimator; see minDemandCurveSamples.
+	MarketKey string
+}
+
+// hourlyDemandMultiplier is a simple double-humped weekday travel profile,
+// peaking at the 07-09 and 17-19 commute windows and quiet overnight.
+func hourlyDemandMultiplier(hour int) float64 {
+	h := ((hour % 24) + 24) % 24
+	switch {
+	case h >= 7 && h <= 9:
+		return 1.8
+	case h >= 17 && h <= 19:
+		return 1.6
+	case h >= 0 && h <= 4:
+		return 0.3
+	default:
+		return 1.0
+	}
+}
+
+// peakDepartureBank returns the hour-of-day with the richest demand, used
+// to pick a new route's DepartureBank.
+func peakDepartureBank() int {
+	best, bestMult := 0, -1.0
+	for h := 0; h < 24; h++ {
+		if m := hourlyDemandMultiplier(h); m > bestMult {
+			best, bestMult = h, m
+		}
+	}
+	return best
+}
+
+var (
+	demandParamsOnce   sync.Once
+	cachedDemandParams demand.Params
+)
+
+// loadedDemandParams lazily loads data/demand.json (falling back to
+// demand.DefaultParams) once per process.
+func loadedDemandParams() demand.Params {
+	demandParamsOnce.Do(func() {
+		p, err := demand.LoadParams("data/demand.json")
+		if err != nil {
+			p = demand.DefaultParams()
+		}
+		cachedDemandParams = p
+	})
+	return cachedDemandParams
+}
+
+// demandEstimateWithOpts estimates sellable seats for a market and price.
+// If opts.MarketKey names a market with a fitted DemandCurve that's seen
+// minDemandCurveSamples or more price-discovery levels, that empirical fit
+// is used in place of the synthetic gravity/logit estimator below.
+func demandEstimateWithOpts(fromAp, toAp Airport, ac Aircraft, freq int, opts demandOptions) int {
+	if opts.MarketKey != "" {
+		if curve, ok := state.MarketDemandCurves[opts.MarketKey]; ok && curve.Samples >= minDemandCurveSamples {
+			price := 0.13 * haversine(fromAp.Latitude, fromAp.Longitude, toAp.Latitude, toAp.Longitude)
+			if opts.Price > 0 {
+				price = opts.Price
+			}
+			if d := int(curve.A + curve.B*price); d > 0 {
+				return min(d, ac.Seats*3)
+			}
+			return 0
+		}
+	}
+
+	dist := haversine(fromAp.Latitude, fromAp.Longitude, toAp.Latitude, toAp.Longitude)
+	params := loadedDemandParams()
+	pool := demand.GravityDemand(dist, demand.CityMass(fromAp.Type, params), demand.CityMass(toAp.Type, params), params)
+	base := int(pool)
+	if base < 35 {
+		base = 35
+	}
+	if base > ac.Seats*3 {
+		base = ac.Seats * 3
+	}
+	basePrice := 0.13 * dist
+	price := basePrice
+	if opts.Price > 0 {
+		price = opts.Price
+	}
+	ratio := 1.0
+	if basePrice > 0 {
+		ratio = price / basePrice
+	}
+	priceElasticity := math.Exp(-3.0 * (ratio - 1.0))
+	if priceElasticity < 0.1 {
+		priceElasticity = 0.1
+	}
+	if priceElasticity > 2.5 {
+		priceElasticity = 2.5
+	}
+	freqBoost := 1.0 + (float64(freq-1) * 0.08)
+	d := int(float64(base) * priceElasticity * freqBoost)
+	if opts.Stopover {
+		d = int(float64(d) * 0.8)
+	}
+	if opts.HourlyProfile {
+		d = int(float64(d) * hourlyDemandMultiplier(opts.Hour))
+	}
+	if d < 20 {
+		d = 20
+	}
+	return d
+}
+
+func findAircraft(id string) (Aircraft, error) {
+	for _, a := range aircraftCatalog {
+		if strings.EqualFold(a.ID, id) {
+			return a, nil
+		}
+	}
+	return Aircraft{}, http.ErrMissingFile
+}
+
+func blockTimeMinutes(distanceKm, cruiseKmh float64, turnaroundMin int) float64 {
+	if cruiseKmh <= 0 {
+		return 0
+	}
+	flightHours := distanceKm / cruiseKmh
+	return (flightHours * 60.0 * 2) + float64(turnaroundMin) // out and back plus turnaround
+}
+
+func maintenanceCost(condition float64) float64 {
+	deficit := 100 - condition
+	if deficit < 5 {
+		deficit = 5
+	}
+	return deficit * 75_000
+}
+
+func maxTemplateUtilization(templateID string) float64 {
+	maxUtil := 0.0
+	for _, ac := range state.Fleet {
+		if ac.TemplateID == templateID && ac.Status == "active" {
+			if ac.Utilization > maxUtil {
+				maxUtil = ac.Utilization
+			}
+		}
+	}
+	return maxUtil
+}
+
+func advanceFleetTimersLocked() {
+	for i := range state.Fleet {
+		ac := &state.Fleet[i]
+		if ac.AvailableIn > 0 {
+			ac.AvailableIn--
+			if ac.AvailableIn <= 0 {
+				ac.AvailableIn = 0
+				if ac.Status == "delivering" || ac.Status == "maintenance" {
+					wasDelivering := ac.Status == "delivering"
+					ac.Status = "active"
+					if wasDelivering {
+						addEvent("aircraft_delivered", *ac)
+					}
+				}
+			}
+		}
+	}
+}
+
+func applyMaintenanceWearLocked() {
+	for i := range state.Fleet {
+		ac := &state.Fleet[i]
+		if ac.Status != "active" || ac.Condition <= 0 {
+			continue
+		}
+		wear := 0.05 + (ac.Utilization/100.0)*0.4
+		ac.Condition -= wear
+		if ac.Condition < 0 {
+			ac.Condition = 0
+		}
+		if ac.Condition < 50 {
+			chance := ((50 - ac.Condition) / 50.0) * 0.25
+			if chance > 0 && rng.Float64() < chance {
+				beginMaintenanceLocked(ac, 3+rng.Intn(3))
+			}
+		}
+	}
+}
+
+func beginMaintenanceLocked(ac *OwnedCraft, ticks int) {
+	if ticks < 1 {
+		ticks = 1
+	}
+	ac.Status = "maintenance"
+	ac.AvailableIn = ticks
+}
+
+// recalcUtilizationLocked recomputes utilization for each owned aircraft based on assigned routes.
+func recalcUtilizationLocked() {
+	// map templateID -> total minutes scheduled
+	scheduled := make(map[string]float64)
+	for _, rt := range state.Routes {
+		mins := rt.BlockMinutes * float64(rt.FrequencyPerDay)
+		scheduled[rt.AircraftID] += mins
+	}
+	countByTemplate := make(map[string]int)
+	for _, ac := range state.Fleet {
+		if ac.Status == "active" {
+			countByTemplate[ac.TemplateID]++
+		}
+	}
+	for i := range state.Fleet {
+		ac := &state.Fleet[i]
+		// assume a 16-hour operating day (960 minutes)
+		util := 0.0
+		if totalMins, ok := scheduled[ac.TemplateID]; ok {
+			activeCount := countByTemplate[ac.TemplateID]
+			if activeCount > 0 {
+				util = (totalMins / (960.0 * float64(activeCount))) * 100.0
+				if util > 100 {
+					util = 100
+				}
+			}
+		}
+		ac.Utilization = util
+	}
+}
+
+// legFractionLocked estimates how far an aircraft is through its current
+// leg's great-circle path, 0 at the origin gate, 1 once it's landed, for the
+// map UI to interpolate a live position from. Ground phases at the origin
+// (Idle, Boarding) don't move the aircraft; taxi-out counts as the start of
+// motion so /fleet/{id}/track shows it rolling before it's airborne.
+func legFractionLocked(ac *OwnedCraft) float64 {
+	total := ac.Phases.TaxiMin + ac.Phases.CruiseMin + ac.Phases.DescentMin
+	if total <= 0 {
+		return 0
+	}
+	var elapsed int
+	switch ac.State {
+	case flightops.Taxi:
+		elapsed = ac.Phases.TaxiMin - ac.Timer
+	case flightops.Cruise:
+		elapsed = ac.Phases.TaxiMin + (ac.Phases.CruiseMin - ac.Timer)
+	case flightops.Descent:
+		elapsed = ac.Phases.TaxiMin + ac.Phases.CruiseMin + (ac.Phases.DescentMin - ac.Timer)
+	case flightops.Turnaround, flightops.Idle:
+		elapsed = total
+	default: // Boarding
+		elapsed = 0
+	}
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	if elapsed > total {
+		elapsed = total
+	}
+	return float64(elapsed) / float64(total)
+}
+
+// findRouteForAcLocked looks up the route this specific aircraft is
+// rostered onto. Routes created after chunk0-5 carry an explicit
+// AssignedCraftIDs roster from flightops.AssignRotation; routes loaded
+// from an older savegame have none, so fall back to the original
+// template-match heuristic. Callers must hold stateMu.
+func findRouteForAcLocked(ac *OwnedCraft) *Route {
+	for i := range state.Routes {
+		rt := &state.Routes[i]
+		if len(rt.AssignedCraftIDs) > 0 {
+			for _, id := range rt.AssignedCraftIDs {
+				if id == ac.ID {
+					return rt
+				}
+			}
+			continue
+		}
+		if rt.AircraftID == ac.TemplateID {
+			return rt
+		}
+	}
+	return nil
+}
+
+// buildGTFSRTFeedLocked renders the fleet and route state as a GTFS-Realtime
+// feed: a VehiclePosition plus a TripUpdate per aircraft that isn't Idle, and
+// an Alert for every route a curfew or validateCapacityLocked would block.
+// Callers must hold stateMu.
+func buildGTFSRTFeedLocked() gtfsrt.FeedMessage {
+	now := time.Now()
+	var entities []gtfsrt.FeedEntity
+
+	for i := range state.Fleet {
+		ac := &state.Fleet[i]
+		if ac.State == flightops.Idle {
+			continue
+		}
+		tripID := ac.FlightPlan.Origin + "-" + ac.FlightPlan.Dest
+		if rt := findRouteForAcLocked(ac); rt != nil {
+			tripID = rt.ID
+		}
+
+		var pos gtfsrt.Position
+		if origin, hasOrigin := airportsByIdent[ac.FlightPlan.Origin]; hasOrigin {
+			if dest, hasDest := airportsByIdent[ac.FlightPlan.Dest]; hasDest {
+				lat, lon := flightops.InterpolatePosition(origin.Latitude, origin.Longitude, dest.Latitude, dest.Longitude, legFractionLocked(ac))
+				pos = gtfsrt.Position{Latitude: lat, Longitude: lon}
+			}
+		}
+
+		entities = append(entities, gtfsrt.FeedEntity{
+			ID: "vehicle-" + ac.ID,
+			VehiclePosition: &gtfsrt.VehiclePosition{
+				VehicleID:     ac.ID,
+				TripID:        tripID,
+				CurrentStopID: ac.Location,
+				Position:      pos,
+				Timestamp:     now.Unix(),
+			},
+		})
+		entities = append(entities, gtfsrt.FeedEntity{
+			ID: "trip-" + ac.ID,
+			TripUpdate: &gtfsrt.TripUpdate{
+				TripID:    tripID,
+				VehicleID: ac.ID,
+				StopTimeUpdates: []gtfsrt.StopTimeUpdate{
+					{StopID: ac.FlightPlan.Dest, ArrivalTime: now.Add(time.Duration(ac.Timer) * time.Minute).Unix()},
+				},
+			},
+		})
+	}
+
+	for i := range state.Routes {
+		rt := state.Routes[i]
+		if rt.CurfewBlocked {
+			entities = append(entities, gtfsrt.FeedEntity{
+				ID: "alert-curfew-" + rt.ID,
+				Alert: &gtfsrt.Alert{
+					InformedTripID: rt.ID,
+					Effect:         "SIGNIFICANT_DELAYS",
+					HeaderText:     fmt.Sprintf("%s-%s blocked by curfew", rt.From, rt.To),
+				},
+			})
+		}
+		if err := validateCapacityLocked(rt); err != nil {
+			entities = append(entities, gtfsrt.FeedEntity{
+				ID: "alert-capacity-" + rt.ID,
+				Alert: &gtfsrt.Alert{
+					InformedTripID: rt.ID,
+					Effect:         "MODIFIED_SERVICE",
+					HeaderText:     err.Error(),
+				},
 			})
 		}
 	}
 
-	totalRevenue := 0.0
-	totalCost := 0.0
-	totalSold := 0
-	totalDemand := 0
-	totalBlock := 0.0
-	totalFees := 0.0
-	for _, l := range legs {
-		totalRevenue += l.revenue
-		totalCost += l.cost
-		totalSold += l.sold
-		totalDemand += l.demand
-		totalBlock += l.blockMin
-		totalFees += l.fees
+	return gtfsrt.NewFeedMessage(entities)
+}
+
+// handleGTFSRTVehiclesJSON is the human/debug-friendly mirror of
+// handleGTFSRTVehiclesPB: same FeedMessage, encoded as JSON.
+func handleGTFSRTVehiclesJSON(w http.ResponseWriter, r *http.Request) {
+	stateMu.Lock()
+	feed := buildGTFSRTFeedLocked()
+	stateMu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(feed)
+}
+
+// handleGTFSRTVehiclesPB serves the fleet as a binary transit_realtime
+// FeedMessage for external mapping tools. Binary marshaling is only wired
+// up when built with `-tags gtfsrt` (see pkg/gtfsrt); otherwise this
+// reports 501 so callers fall back to /gtfs-rt/vehicles.json.
+func handleGTFSRTVehiclesPB(w http.ResponseWriter, r *http.Request) {
+	stateMu.Lock()
+	feed := buildGTFSRTFeedLocked()
+	stateMu.Unlock()
+
+	data, err := feed.MarshalPB()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotImplemented)
+		return
 	}
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.Write(data)
+}
 
-	legsPerTrip := float64(len(legs))
-	avgRevenuePerLeg := totalRevenue / legsPerTrip
-	avgCostPerLeg := totalCost / legsPerTrip
-	avgFeesPerLeg := totalFees / legsPerTrip
-	loadFactor := float64(totalSold) / float64(ac.Seats*len(legs))
+// hourOfTick returns the hour-of-day (0-23) a given simulation tick falls
+// in, using the 1-tick-per-minute convention advanceTickLocked runs on.
+func hourOfTick(tick int) int {
+	return (tick / 60) % 24
+}
 
-	profitPerTick := (totalRevenue - totalCost) * float64(freq)
-	curfewBlocked := fromAp.Curfew || toAp.Curfew
-	if hasVia && viaAp.Curfew {
-		curfewBlocked = true
+// hedgeTerms returns the locked fraction and up-front premium rate for a
+// /fuel/hedge mode, mirroring low/medium/high gas-fee tiers.
+func hedgeTerms(mode string) (lockedFraction, premiumRate float64, ok bool) {
+	switch mode {
+	case "low":
+		return 0.25, 0.02, true
+	case "medium":
+		return 0.50, 0.05, true
+	case "high":
+		return 0.90, 0.10, true
+	default:
+		return 0, 0, false
 	}
+}
 
-	avgPricePerSeat := userPrice
+// effectiveFuelMultiplierLocked blends the live spot FuelMarket.Price with
+// whatever fraction of consumption is currently hedge-locked, weighted by
+// each active hedge's LockedFraction (capped at 100% locked in aggregate).
+func effectiveFuelMultiplierLocked() float64 {
+	lockedFrac := 0.0
+	lockedCost := 0.0
+	for _, h := range state.FuelMarket.Hedges {
+		if h.ExpiresAtTick <= state.Tick {
+			continue
+		}
+		lockedFrac += h.LockedFraction
+		lockedCost += h.LockedFraction * h.LockedPrice
+	}
+	if lockedFrac > 1 {
+		lockedCost *= 1 / lockedFrac
+		lockedFrac = 1
+	}
+	return lockedCost + (1-lockedFrac)*state.FuelMarket.Price
+}
 
-	route := Route{
-		ID:                strconv.FormatInt(time.Now().UnixNano(), 10),
-		From:              fromID,
-		To:                toID,
-		Via:               viaID,
-		AircraftID:        ac.ID,
-		FrequencyPerDay:   freq,
-		EstimatedDemand:   totalDemand,
-		PricePerSeat:      avgPricePerSeat,
-		UserPrice:         userPrice,
-		EstRevenueTick:    totalRevenue * float64(freq),
-		EstCostTick:       totalCost * float64(freq),
-		LoadFactor:        loadFactor,
-		RevenuePerLeg:     avgRevenuePerLeg,
-		CostPerLeg:        avgCostPerLeg,
-		LandingFeesPerLeg: avgFeesPerLeg,
-		ProfitPerTick:     profitPerTick,
-		SeatsSoldPerLeg:   totalSold / len(legs),
-		BlockMinutes:      totalBlock,
-		CurfewBlocked:     curfewBlocked,
-		LastTickRevenue:   totalRevenue * float64(freq),
-		LastTickLoad:      loadFactor,
+// evolveFuelMarketLocked steps the fuel-price multiplier one tick along a
+// mean-reverting (Ornstein-Uhlenbeck-style) random walk and drops expired
+// hedges.
+func evolveFuelMarketLocked() {
+	fm := &state.FuelMarket
+	p := fm.Price + fm.Theta*(fm.Mu-fm.Price) + fm.Sigma*rng.NormFloat64()
+	if p < 0.5 {
+		p = 0.5
 	}
-	return route, nil
+	if p > 2.5 {
+		p = 2.5
+	}
+	fm.Price = p
+
+	live := fm.Hedges[:0]
+	for _, h := range fm.Hedges {
+		if h.ExpiresAtTick > state.Tick {
+			live = append(live, h)
+		}
+	}
+	fm.Hedges = live
 }
 
-func haversine(lat1, lon1, lat2, lon2 float64) float64 {
-	const R = 6371.0
-	dLat := toRad(lat2 - lat1)
-	dLon := toRad(lon2 - lon1)
-	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
-	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
-	return R * c
+// fleetDailyFuelSpendEstimate approximates the fleet's total daily fuel-only
+// spend from each route's BlockMinutes, for sizing a hedge's premium. It's
+// an estimate: BlockMinutes already has turnaround time baked in, so the
+// back-computed distance runs a little long.
+func fleetDailyFuelSpendEstimate() float64 {
+	total := 0.0
+	for _, rt := range state.Routes {
+		ac, err := findAircraft(rt.AircraftID)
+		if err != nil {
+			continue
+		}
+		distKm := (rt.BlockMinutes / 60.0) * ac.CruiseKmh
+		total += distKm * ac.FuelCost * float64(rt.FrequencyPerDay)
+	}
+	return total
 }
 
-func toRad(deg float64) float64 {
-	return deg * math.Pi / 180
+// applyFuelHedgeSavingsLocked distributes this tick's hedge savings (or
+// losses, if the spot price fell below a locked price) across active
+// hedges, proportional to each one's share of locked fraction.
+func applyFuelHedgeSavingsLocked(fuelCostAtSpot, fuelCostEffective float64) {
+	delta := fuelCostAtSpot - fuelCostEffective // positive = hedges saved money
+	if delta == 0 || len(state.FuelMarket.Hedges) == 0 {
+		return
+	}
+	totalFrac := 0.0
+	for _, h := range state.FuelMarket.Hedges {
+		if h.ExpiresAtTick > state.Tick {
+			totalFrac += h.LockedFraction
+		}
+	}
+	if totalFrac <= 0 {
+		return
+	}
+	for i := range state.FuelMarket.Hedges {
+		h := &state.FuelMarket.Hedges[i]
+		if h.ExpiresAtTick <= state.Tick {
+			continue
+		}
+		h.RealizedSavings += delta * (h.LockedFraction / totalFrac)
+	}
 }
 
-func min(a, b int) int {
-	if a < b {
-		return a
+// FuelHedgeRequest is the body for POST /fuel/hedge.
+type FuelHedgeRequest struct {
+	Mode   string `json:"mode"` // "low", "medium", or "high"
+	Months int    `json:"months"`
+}
+
+// handleFuelHedge locks a fraction of fuel consumption at the current
+// FuelMarket.Price for N months, charging an up-front premium proportional
+// to the locked notional.
+func handleFuelHedge(w http.ResponseWriter, r *http.Request) {
+	var req FuelHedgeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
-	return b
+	lockedFraction, premiumRate, ok := hedgeTerms(req.Mode)
+	if !ok {
+		http.Error(w, "mode must be low, medium, or high", http.StatusBadRequest)
+		return
+	}
+	months := req.Months
+	if months <= 0 {
+		months = 1
+	}
+
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	notional := fleetDailyFuelSpendEstimate() * lockedFraction * float64(months) * 30
+	premium := notional * premiumRate
+	if premium > state.Cash {
+		http.Error(w, "insufficient cash for hedge premium", http.StatusBadRequest)
+		return
+	}
+	state.Cash -= premium
+
+	hedge := FuelHedge{
+		Mode:           req.Mode,
+		LockedFraction: lockedFraction,
+		LockedPrice:    state.FuelMarket.Price,
+		Premium:        premium,
+		ExpiresAtTick:  state.Tick + months*ticksPerMonth,
+	}
+	state.FuelMarket.Hedges = append(state.FuelMarket.Hedges, hedge)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(hedge)
 }
 
-func maxInt(a, b int) int {
-	if a > b {
-		return a
+// hasActiveAssignedCraftLocked reports whether rt has at least one active
+// (not maintenance/delivering) aircraft to fly it this tick.
+func hasActiveAssignedCraftLocked(rt *Route) bool {
+	if len(rt.AssignedCraftIDs) > 0 {
+		for _, id := range rt.AssignedCraftIDs {
+			for _, ac := range state.Fleet {
+				if ac.ID == id && ac.Status == "active" {
+					return true
+				}
+			}
+		}
+		return false
 	}
-	return b
+	for _, ac := range state.Fleet {
+		if ac.TemplateID == rt.AircraftID && ac.Status == "active" {
+			return true
+		}
+	}
+	return false
 }
 
-func marketKey(a, b string) string {
-	a = strings.ToUpper(a)
-	b = strings.ToUpper(b)
-	if a < b {
-		return a + "-" + b
+// counterfactualLegProfit estimates what one scheduled departure on rt would
+// have earned, using the same revenue/cost shape as the live departure in
+// advanceTickLocked's Turnaround branch, for lost-profit accounting when
+// that departure is skipped instead.
+func counterfactualLegProfit(rt Route) float64 {
+	ac, err := findAircraft(rt.AircraftID)
+	if err != nil {
+		return 0
+	}
+	fromAp, ok := airportsByIdent[strings.ToUpper(rt.From)]
+	if !ok {
+		return 0
+	}
+	dest := rt.To
+	numLegs := 1
+	if rt.Via != "" {
+		dest = rt.Via
+		numLegs = 2
+	}
+	toAp, ok := airportsByIdent[strings.ToUpper(dest)]
+	if !ok {
+		return 0
+	}
+	dist := haversine(fromAp.Latitude, fromAp.Longitude, toAp.Latitude, toAp.Longitude)
+	passengers := int(float64(ac.Seats) * rt.LoadFactor)
+	legPrice := rt.UserPrice / float64(numLegs)
+	revenue := float64(passengers) * legPrice
+	cost := dist*ac.FuelCost*effectiveFuelMultiplierLocked() + 500 + toAp.LandingFee
+	return revenue - cost
+}
+
+func sumFloats(vals []float64) float64 {
+	total := 0.0
+	for _, v := range vals {
+		total += v
+	}
+	return total
+}
+
+// accrueLostProfitLocked tallies, for every route whose scheduled departure
+// bank is this tick's hour but that can't actually depart (no active
+// aircraft, curfew, or a slot cap from validateCapacityLocked), the
+// counterfactual profit it missed out on. Called from advanceTickLocked
+// after applyMaintenanceWearLocked so freshly-grounded aircraft are already
+// reflected in state.Fleet.
+func accrueLostProfitLocked() {
+	state.LostProfitTick = 0
+	hour := hourOfTick(state.Tick)
+	for i := range state.Routes {
+		rt := &state.Routes[i]
+		if hour != rt.DepartureBank {
+			continue
+		}
+
+		blocked := rt.CurfewBlocked || !hasActiveAssignedCraftLocked(rt)
+		if !blocked {
+			if err := validateCapacityLocked(*rt); err != nil {
+				blocked = true
+			}
+		}
+		if !blocked {
+			continue
+		}
+
+		lost := counterfactualLegProfit(*rt)
+		rt.LostProfit += lost
+		rt.LostProfitHistory = append(rt.LostProfitHistory, lost)
+		if over := len(rt.LostProfitHistory) - lostProfitWindow; over > 0 {
+			rt.LostProfit -= sumFloats(rt.LostProfitHistory[:over])
+			rt.LostProfitHistory = rt.LostProfitHistory[over:]
+		}
+		state.LostProfitTick += lost
+	}
+}
+
+// meanAbsDelta is the load-factor ATR: the mean absolute tick-over-tick
+// change across vals.
+func meanAbsDelta(vals []float64) float64 {
+	if len(vals) < 2 {
+		return 0
+	}
+	total := 0.0
+	for i := 1; i < len(vals); i++ {
+		delta := vals[i] - vals[i-1]
+		if delta < 0 {
+			delta = -delta
+		}
+		total += delta
+	}
+	return total / float64(len(vals)-1)
+}
+
+// trendingDown reports whether vals is non-increasing and has at least two
+// points to compare.
+func trendingDown(vals []float64) bool {
+	if len(vals) < 2 {
+		return false
+	}
+	for i := 1; i < len(vals); i++ {
+		if vals[i] > vals[i-1] {
+			return false
+		}
 	}
-	return b + "-" + a
+	return true
 }
 
-func validateCapacityLocked(route Route) error {
-	activeCount := 0
-	for _, ac := range state.Fleet {
-		if ac.TemplateID == route.AircraftID && ac.Status == "active" {
-			activeCount++
+// pinnedAtCapacity reports whether every value in vals is at (or within
+// rounding of) a load factor of 1.0.
+func pinnedAtCapacity(vals []float64) bool {
+	if len(vals) == 0 {
+		return false
+	}
+	for _, v := range vals {
+		if v < 0.999 {
+			return false
 		}
 	}
-	if activeCount == 0 {
-		return http.ErrBodyNotAllowed // no available aircraft of that type
+	return true
+}
+
+// autoManageRouteLocked appends this departure's load/profit sample to rt's
+// rolling history, recomputes its ATR/peak/trough, and — when rt.AutoManage
+// is on — cuts or raises UserPrice by k*LoadATR*UserPrice (bounded to
+// autoManagePriceStepCap*UserPrice per departure) or suspends the route once
+// cumulative window profit drops below -Callback*PeakProfit. Called from
+// advanceTickLocked right after a live departure sets LastTickLoad and
+// ProfitPerTick.
+func autoManageRouteLocked(rt *Route) {
+	window := rt.AutoManageWindow
+	if window <= 0 {
+		window = defaultAutoManageWindow
 	}
 
-	totalMins := route.BlockMinutes * float64(route.FrequencyPerDay)
-	for _, rt := range state.Routes {
-		if rt.AircraftID == route.AircraftID {
-			totalMins += rt.BlockMinutes * float64(rt.FrequencyPerDay)
-		}
+	rt.LoadHistory = append(rt.LoadHistory, rt.LastTickLoad)
+	if over := len(rt.LoadHistory) - window; over > 0 {
+		rt.LoadHistory = rt.LoadHistory[over:]
 	}
-	// capacity in minutes per day
-	if totalMins > float64(activeCount)*960.0 {
-		return fmt.Errorf("insufficient aircraft time (over 16h/day for %s fleet)", route.AircraftID)
+	rt.ProfitHistory = append(rt.ProfitHistory, rt.ProfitPerTick)
+	if over := len(rt.ProfitHistory) - window; over > 0 {
+		rt.ProfitHistory = rt.ProfitHistory[over:]
 	}
+	rt.LoadATR = meanAbsDelta(rt.LoadHistory)
 
-	addSlotUse := func(ident string, freq int, slotUse map[string]int) {
-		if ident == "" || freq == 0 {
-			return
-		}
-		slotUse[strings.ToUpper(ident)] += freq
+	cumProfit := sumFloats(rt.ProfitHistory)
+	if cumProfit > rt.PeakProfit {
+		rt.PeakProfit = cumProfit
+	}
+	if cumProfit < rt.TroughProfit {
+		rt.TroughProfit = cumProfit
 	}
 
-	// slot constraints per airport
-	slotUse := make(map[string]int)
-	addSlotUse(route.From, route.FrequencyPerDay, slotUse)
-	addSlotUse(route.To, route.FrequencyPerDay, slotUse)
-	addSlotUse(route.Via, route.FrequencyPerDay, slotUse)
-	for _, rt := range state.Routes {
-		addSlotUse(rt.From, rt.FrequencyPerDay, slotUse)
-		addSlotUse(rt.To, rt.FrequencyPerDay, slotUse)
-		addSlotUse(rt.Via, rt.FrequencyPerDay, slotUse)
+	if !rt.AutoManage || rt.Suspended {
+		return
 	}
-	for ident, used := range slotUse {
-		if ap, ok := airportsByIdent[ident]; ok && ap.SlotsPerDay > 0 && used > ap.SlotsPerDay {
-			return fmt.Errorf("slot limit exceeded at %s (%d/%d)", ident, used, ap.SlotsPerDay)
-		}
+
+	callback := rt.AutoManageCallback
+	if callback <= 0 {
+		callback = defaultAutoManageCallback
+	}
+	if rt.PeakProfit > 0 && cumProfit < -callback*rt.PeakProfit {
+		rt.Suspended = true
+		return
 	}
 
-	// curfew: ensure total block minutes at airport fits within allowed hours
-	blockUse := make(map[string]float64)
-	addBlockUse := func(ident string, mins float64, freq int, blockUse map[string]float64) {
-		if ident == "" || freq == 0 || mins <= 0 {
-			return
-		}
-		blockUse[strings.ToUpper(ident)] += mins * float64(freq)
+	if len(rt.LoadHistory) < autoManageSmootherWindow {
+		return
 	}
-	// include new route usage
-	addBlockUse(route.From, route.BlockMinutes, route.FrequencyPerDay, blockUse)
-	addBlockUse(route.To, route.BlockMinutes, route.FrequencyPerDay, blockUse)
-	addBlockUse(route.Via, route.BlockMinutes, route.FrequencyPerDay, blockUse)
-	for _, rt := range state.Routes {
-		addBlockUse(rt.From, rt.BlockMinutes, rt.FrequencyPerDay, blockUse)
-		addBlockUse(rt.To, rt.BlockMinutes, rt.FrequencyPerDay, blockUse)
-		addBlockUse(rt.Via, rt.BlockMinutes, rt.FrequencyPerDay, blockUse)
+	recent := rt.LoadHistory[len(rt.LoadHistory)-autoManageSmootherWindow:]
+
+	k := rt.AutoManageK
+	if k <= 0 {
+		k = defaultAutoManageK
 	}
-	for ident, mins := range blockUse {
-		ap, ok := airportsByIdent[ident]
-		if !ok || !ap.Curfew {
-			continue
-		}
-		avail := curfewAvailableMinutes(ap.CurfewStart, ap.CurfewEnd)
-		if mins > avail {
-			return fmt.Errorf("curfew hours limit at %s (%.0f/%.0f mins)", ident, mins, avail)
+	step := k * rt.LoadATR * rt.UserPrice
+	if maxStep := rt.UserPrice * autoManagePriceStepCap; step > maxStep {
+		step = maxStep
+	}
+
+	switch {
+	case trendingDown(recent) && rt.ProfitPerTick < 0:
+		rt.UserPrice -= step
+		if rt.UserPrice < 0 {
+			rt.UserPrice = 0
 		}
+	case pinnedAtCapacity(recent):
+		rt.UserPrice += step
 	}
-	return nil
 }
 
-func marketExistsLocked(from, to string) bool {
-	key := marketKey(from, to)
-	for _, rt := range state.Routes {
-		if marketKey(rt.From, rt.To) == key {
-			return true
+// yieldATR is the trailing-band load-factor ATR: the mean of
+// max(load)-min(load) across every consecutive subWindow-sized slice of
+// vals.
+func yieldATR(vals []float64, subWindow int) float64 {
+	if subWindow <= 0 || len(vals) < subWindow {
+		return 0
+	}
+	total := 0.0
+	n := 0
+	for i := 0; i+subWindow <= len(vals); i++ {
+		sub := vals[i : i+subWindow]
+		lo, hi := sub[0], sub[0]
+		for _, v := range sub[1:] {
+			if v < lo {
+				lo = v
+			}
+			if v > hi {
+				hi = v
+			}
 		}
+		total += hi - lo
+		n++
 	}
-	return false
+	if n == 0 {
+		return 0
+	}
+	return total / float64(n)
 }
 
-func demandEstimate(fromAp, toAp Airport, ac Aircraft, freq int) int {
-	return demandEstimateWithOpts(fromAp, toAp, ac, freq, demandOptions{})
-}
+// yieldManageRouteLocked appends this departure's load to rt's trailing
+// YieldLoadWindow and, when rt.YieldManage is on, nudges UserPrice off the
+// TrailingActivationRatio/TrailingCallbackRate bands: each activation ratio
+// the trailing load has climbed past raises price by (band index+1) *
+// YieldK * YieldATR * UserPrice, and each callback rate it has dropped below cuts price
+// by the same step. The candidate price is checked against
+// demandEstimateWithOpts before it's applied — a hike that would crater
+// demand is dropped — so the loop can't chase its own tail, then clamped
+// to [YieldMinFare, YieldMaxFare]. Called from advanceTickLocked right
+// before a live departure is priced, alongside autoManageRouteLocked.
+func yieldManageRouteLocked(rt *Route, fromAp, toAp Airport) {
+	window := rt.YieldWindow
+	if window <= 0 {
+		window = defaultYieldWindow
+	}
+	rt.YieldLoadWindow = append(rt.YieldLoadWindow, rt.LastTickLoad)
+	if over := len(rt.YieldLoadWindow) - window; over > 0 {
+		rt.YieldLoadWindow = rt.YieldLoadWindow[over:]
+	}
+	rt.YieldATR = yieldATR(rt.YieldLoadWindow, yieldATRSubWindow)
 
-type demandOptions struct {
-	Stopover bool
-	Price    float64
-}
+	if !rt.YieldManage || len(rt.YieldLoadWindow) < yieldATRSubWindow {
+		return
+	}
 
-func demandEstimateWithOpts(fromAp, toAp Airport, ac Aircraft, freq int, opts demandOptions) int {
-	dist := haversine(fromAp.Latitude, fromAp.Longitude, toAp.Latitude, toAp.Longitude)
-	base := 60 + int(dist/45)
-	if base < 35 {
-		base = 35
+	k := rt.YieldK
+	if k <= 0 {
+		k = defaultYieldK
 	}
-	if base > ac.Seats*3 {
-		base = ac.Seats * 3
+	trailingLoad := rt.YieldLoadWindow[len(rt.YieldLoadWindow)-1]
+	step := k * rt.YieldATR * rt.UserPrice
+
+	newPrice := rt.UserPrice
+	for i, activation := range rt.TrailingActivationRatio {
+		if trailingLoad >= activation {
+			newPrice += step * float64(i+1)
+		}
 	}
-	basePrice := 0.13 * dist
-	price := basePrice
-	if opts.Price > 0 {
-		price = opts.Price
+	for i, callback := range rt.TrailingCallbackRate {
+		if trailingLoad <= callback {
+			newPrice -= step * float64(i+1)
+		}
 	}
-	ratio := 1.0
-	if basePrice > 0 {
-		ratio = price / basePrice
+	if newPrice == rt.UserPrice {
+		return
 	}
-	priceElasticity := math.Exp(-3.0 * (ratio - 1.0))
-	if priceElasticity < 0.1 {
-		priceElasticity = 0.1
+
+	ac, err := findAircraft(rt.AircraftID)
+	if err != nil {
+		return
 	}
-	if priceElasticity > 2.5 {
-		priceElasticity = 2.5
+	if sold := demandEstimateWithOpts(fromAp, toAp, ac, rt.FrequencyPerDay, demandOptions{Price: newPrice, MarketKey: marketKey(rt.From, rt.To)}); sold == 0 && newPrice > rt.UserPrice {
+		return
 	}
-	freqBoost := 1.0 + (float64(freq-1) * 0.08)
-	d := int(float64(base) * priceElasticity * freqBoost)
-	if opts.Stopover {
-		d = int(float64(d) * 0.8)
+
+	if newPrice < rt.YieldMinFare {
+		newPrice = rt.YieldMinFare
 	}
-	if d < 20 {
-		d = 20
+	if rt.YieldMaxFare > 0 && newPrice > rt.YieldMaxFare {
+		newPrice = rt.YieldMaxFare
 	}
-	return d
+	if newPrice < 0 {
+		newPrice = 0
+	}
+	rt.UserPrice = newPrice
 }
 
-func findAircraft(id string) (Aircraft, error) {
-	for _, a := range aircraftCatalog {
-		if strings.EqualFold(a.ID, id) {
-			return a, nil
+// runPriceDiscoveryProbeLocked starts a grid price-discovery probe on rt:
+// steps price levels spaced deviation apart around rt.UserPrice (the
+// midpoint), price*(1+k*deviation) for k running evenly from -(steps-1)/2
+// to +(steps-1)/2, each held for ticksPerStep departures. priceDiscoveryRouteLocked
+// drives the probe forward from advanceTickLocked and fits the final curve.
+func runPriceDiscoveryProbeLocked(rt *Route, deviation float64, steps, ticksPerStep int) error {
+	if steps < minDemandCurveSamples {
+		return fmt.Errorf("price discovery needs at least %d steps", minDemandCurveSamples)
+	}
+	if deviation <= 0 {
+		return fmt.Errorf("deviation must be positive")
+	}
+	if ticksPerStep <= 0 {
+		return fmt.Errorf("ticksPerStep must be positive")
+	}
+	if rt.UserPrice <= 0 {
+		return fmt.Errorf("route has no price to probe around")
+	}
+
+	levels := make([]float64, steps)
+	mid := float64(steps-1) / 2
+	for i := range levels {
+		k := float64(i) - mid
+		levels[i] = rt.UserPrice * (1 + k*deviation)
+		if levels[i] < 0 {
+			levels[i] = 0
 		}
 	}
-	return Aircraft{}, http.ErrMissingFile
+
+	rt.PriceDiscoveryActive = true
+	rt.PriceDiscoveryLevels = levels
+	rt.PriceDiscoverySold = make([]int, steps)
+	rt.PriceDiscoveryHits = make([]int, steps)
+	rt.PriceDiscoveryStep = 0
+	rt.PriceDiscoveryTicksPerStep = ticksPerStep
+	rt.PriceDiscoveryTicksInStep = 0
+	rt.UserPrice = levels[0]
+	return nil
 }
 
-func blockTimeMinutes(distanceKm, cruiseKmh float64, turnaroundMin int) float64 {
-	if cruiseKmh <= 0 {
-		return 0
+// priceDiscoveryRouteLocked records this departure's passengers against the
+// probe's current price level and, once PriceDiscoveryTicksPerStep
+// departures have landed in that level, either advances UserPrice to the
+// next level or — on the last level — fits the sampled (price, sold)
+// points into a DemandCurve, persists it to state.MarketDemandCurves, and
+// sets UserPrice to the revenue-maximizing point on that curve. Called
+// from advanceTickLocked in place of autoManageRouteLocked/
+// yieldManageRouteLocked: while a probe is active the tick loop skips
+// those controllers for the route, so UserPrice is driven here instead.
+func priceDiscoveryRouteLocked(rt *Route, passengers int) {
+	if !rt.PriceDiscoveryActive {
+		return
 	}
-	flightHours := distanceKm / cruiseKmh
-	return (flightHours * 60.0 * 2) + float64(turnaroundMin) // out and back plus turnaround
-}
 
-func maintenanceCost(condition float64) float64 {
-	deficit := 100 - condition
-	if deficit < 5 {
-		deficit = 5
+	rt.PriceDiscoverySold[rt.PriceDiscoveryStep] += passengers
+	rt.PriceDiscoveryHits[rt.PriceDiscoveryStep]++
+	rt.PriceDiscoveryTicksInStep++
+	if rt.PriceDiscoveryTicksInStep < rt.PriceDiscoveryTicksPerStep {
+		return
 	}
-	return deficit * 75_000
-}
 
-func maxTemplateUtilization(templateID string) float64 {
-	maxUtil := 0.0
-	for _, ac := range state.Fleet {
-		if ac.TemplateID == templateID && ac.Status == "active" {
-			if ac.Utilization > maxUtil {
-				maxUtil = ac.Utilization
-			}
-		}
+	rt.PriceDiscoveryStep++
+	rt.PriceDiscoveryTicksInStep = 0
+	if rt.PriceDiscoveryStep < len(rt.PriceDiscoveryLevels) {
+		rt.UserPrice = rt.PriceDiscoveryLevels[rt.PriceDiscoveryStep]
+		return
 	}
-	return maxUtil
-}
 
-func advanceFleetTimersLocked() {
-	for i := range state.Fleet {
-		ac := &state.Fleet[i]
-		if ac.AvailableIn > 0 {
-			ac.AvailableIn--
-			if ac.AvailableIn <= 0 {
-				ac.AvailableIn = 0
-				if ac.Status == "delivering" || ac.Status == "maintenance" {
-					ac.Status = "active"
-				}
-			}
+	avgSold := make([]float64, len(rt.PriceDiscoveryLevels))
+	for i, hits := range rt.PriceDiscoveryHits {
+		if hits > 0 {
+			avgSold[i] = float64(rt.PriceDiscoverySold[i]) / float64(hits)
 		}
 	}
-}
+	curve := fitDemandCurve(rt.PriceDiscoveryLevels, avgSold)
 
-func applyMaintenanceWearLocked() {
-	for i := range state.Fleet {
-		ac := &state.Fleet[i]
-		if ac.Status != "active" || ac.Condition <= 0 {
-			continue
-		}
-		wear := 0.05 + (ac.Utilization/100.0)*0.4
-		ac.Condition -= wear
-		if ac.Condition < 0 {
-			ac.Condition = 0
-		}
-		if ac.Condition < 50 {
-			chance := ((50 - ac.Condition) / 50.0) * 0.25
-			if chance > 0 && rng.Float64() < chance {
-				beginMaintenanceLocked(ac, 3+rng.Intn(3))
-			}
-		}
+	if state.MarketDemandCurves == nil {
+		state.MarketDemandCurves = make(map[string]DemandCurve)
 	}
+	state.MarketDemandCurves[marketKey(rt.From, rt.To)] = curve
+
+	rt.UserPrice = revenueMaximizingPrice(curve, rt.PriceDiscoveryLevels)
+	rt.PriceDiscoveryActive = false
 }
 
-func beginMaintenanceLocked(ac *OwnedCraft, ticks int) {
-	if ticks < 1 {
-		ticks = 1
+// fitDemandCurve least-squares fits sold = A + B*price over the sampled
+// (prices[i], sold[i]) points, one per probed level.
+func fitDemandCurve(prices, sold []float64) DemandCurve {
+	n := float64(len(prices))
+	if n == 0 {
+		return DemandCurve{}
 	}
-	ac.Status = "maintenance"
-	ac.AvailableIn = ticks
+	var sumX, sumY, sumXY, sumXX float64
+	for i := range prices {
+		sumX += prices[i]
+		sumY += sold[i]
+		sumXY += prices[i] * sold[i]
+		sumXX += prices[i] * prices[i]
+	}
+	denom := n*sumXX - sumX*sumX
+	if denom == 0 {
+		return DemandCurve{A: sumY / n, Samples: len(prices)}
+	}
+	b := (n*sumXY - sumX*sumY) / denom
+	a := (sumY - b*sumX) / n
+	return DemandCurve{A: a, B: b, Samples: len(prices)}
 }
 
-// recalcUtilizationLocked recomputes utilization for each owned aircraft based on assigned routes.
-func recalcUtilizationLocked() {
-	// map templateID -> total minutes scheduled
-	scheduled := make(map[string]float64)
-	for _, rt := range state.Routes {
-		mins := rt.BlockMinutes * float64(rt.FrequencyPerDay)
-		scheduled[rt.AircraftID] += mins
-	}
-	countByTemplate := make(map[string]int)
-	for _, ac := range state.Fleet {
-		if ac.Status == "active" {
-			countByTemplate[ac.TemplateID]++
+// revenueMaximizingPrice returns the price maximizing
+// revenue(price) = price*(A+B*price) over curve, clamped to the range of
+// levels actually probed (the fit isn't trustworthy outside it). A
+// non-decreasing curve (B >= 0) has no interior maximum, so it falls back
+// to the highest probed level.
+func revenueMaximizingPrice(curve DemandCurve, levels []float64) float64 {
+	lo, hi := levels[0], levels[0]
+	for _, p := range levels[1:] {
+		if p < lo {
+			lo = p
 		}
-	}
-	for i := range state.Fleet {
-		ac := &state.Fleet[i]
-		// assume a 16-hour operating day (960 minutes)
-		util := 0.0
-		if totalMins, ok := scheduled[ac.TemplateID]; ok {
-			activeCount := countByTemplate[ac.TemplateID]
-			if activeCount > 0 {
-				util = (totalMins / (960.0 * float64(activeCount))) * 100.0
-				if util > 100 {
-					util = 100
-				}
-			}
+		if p > hi {
+			hi = p
 		}
-		ac.Utilization = util
 	}
+	if curve.B >= 0 {
+		return hi
+	}
+	vertex := -curve.A / (2 * curve.B)
+	if vertex < lo {
+		return lo
+	}
+	if vertex > hi {
+		return hi
+	}
+	return vertex
 }
 
 func advanceTickLocked() {
 	// 1 tick = 1 minute of "simulation time" for timers
-	
+
+	evolveFuelMarketLocked()
+	evolveCommodityMarketLocked()
+	fuelMultiplier := effectiveFuelMultiplierLocked()
+
 	totalRevenue := 0.0
 	totalCost := 0.0
-
-	// Helper to find route for aircraft
-	findRouteForAc := func(acID string) *Route {
-		for i := range state.Routes {
-			if state.Routes[i].AircraftID == acID {
-				return &state.Routes[i]
-			}
+	totalFuelAtSpot := 0.0
+	totalFuelEffective := 0.0
+
+	// logFlightEvent records a departure/arrival/diversion into the
+	// append-only replay log and broadcasts it to /stream subscribers.
+	logFlightEvent := func(eventType string, ac *OwnedCraft, detail string) {
+		routeID := ""
+		if rt := findRouteForAcLocked(ac); rt != nil {
+			routeID = rt.ID
+		}
+		ev := flightops.Event{
+			Tick:       state.Tick,
+			Time:       time.Now(),
+			Type:       eventType,
+			AircraftID: ac.ID,
+			RouteID:    routeID,
+			From:       ac.FlightPlan.Origin,
+			To:         ac.FlightPlan.Dest,
+			Detail:     detail,
+		}
+		if err := flightops.AppendEvent(eventLogPath, ev); err != nil {
+			log.Printf("flightops: failed to append event to %s: %v", eventLogPath, err)
 		}
-		return nil
+		addEvent(eventType, ev)
 	}
 
 	for i := range state.Fleet {
@@ -1487,27 +4554,74 @@ func advanceTickLocked() {
 		}
 
 		if ac.State == "" {
-			ac.State = "Idle"
+			ac.State = flightops.Idle
 		}
 
-		// State Machine
+		// State Machine: Idle -> Boarding -> Taxi -> Cruise -> Descent ->
+		// Turnaround -> Idle. Only Turnaround (ground stop at the far end)
+		// and the Idle->Boarding dispatch plan a leg; the rest just counts
+		// down the phase timer computed for that leg.
 		switch ac.State {
-		case "Flying":
+		case flightops.Boarding:
+			ac.Timer--
+			if ac.Timer <= 0 {
+				ac.State = flightops.Taxi
+				ac.Timer = ac.Phases.Duration(flightops.Taxi)
+			}
+
+		case flightops.Taxi:
+			ac.Timer--
+			if ac.Timer <= 0 {
+				ac.State = flightops.Cruise
+				ac.Timer = ac.Phases.Duration(flightops.Cruise)
+				logFlightEvent("departure", ac, "")
+			}
+
+		case flightops.Cruise:
+			ac.Timer--
+			if ac.Timer <= 0 {
+				ac.State = flightops.Descent
+				ac.Timer = ac.Phases.Duration(flightops.Descent)
+			}
+
+		case flightops.Descent:
 			ac.Timer--
 			if ac.Timer <= 0 {
 				// Arrived
 				ac.Location = ac.FlightPlan.Dest
-				ac.State = "Turnaround"
-				ac.Timer = ac.Turnaround
+				ac.State = flightops.Turnaround
+				ac.Timer = ac.Phases.Duration(flightops.Turnaround)
+				logFlightEvent("arrival", ac, "")
 			}
 
-		case "Turnaround":
+		case flightops.Turnaround:
 			ac.Timer--
 			if ac.Timer <= 0 {
-				// Turnaround complete, ready to fly next leg
-				rt := findRouteForAc(ac.ID)
+				// Turnaround complete, ready to plan the next leg
+				rt := findRouteForAcLocked(ac)
 				if rt == nil {
-					ac.State = "Idle"
+					ac.State = flightops.Idle
+					continue
+				}
+				if rt.Suspended {
+					// The auto-manager tripped its trailing stop; stay on the
+					// ground until a user re-enables the route.
+					ac.State = flightops.Idle
+					continue
+				}
+				if slotMultiplierLocked(rt.From) <= 0 || slotMultiplierLocked(rt.To) <= 0 || (rt.Via != "" && slotMultiplierLocked(rt.Via) <= 0) {
+					// A live closure-grade disruption (weather, NOTAM) has shut
+					// one of this route's airports; hold on the ground instead
+					// of dispatching into a diversion. accrueLostProfitLocked
+					// counts the missed departure.
+					addEvent("disruption_grounded", map[string]string{"route_id": rt.ID, "aircraft_id": ac.ID})
+					ac.State = flightops.Idle
+					continue
+				}
+				if hourOfTick(state.Tick) != rt.DepartureBank {
+					// Not this route's scheduled departure bank yet; hold at
+					// the gate and check again next tick.
+					ac.Timer = 1
 					continue
 				}
 
@@ -1538,7 +4652,11 @@ func advanceTickLocked() {
 						dest = rt.From
 					}
 				} else {
-					// Mismatch fallback
+					// Mismatch fallback: the aircraft's last known location
+					// doesn't match any endpoint of its own route (e.g. the
+					// route was edited out from under it). Divert it back
+					// to the route's origin rather than stranding it.
+					logFlightEvent("diversion", ac, fmt.Sprintf("reset from %s to %s", ac.Location, rt.From))
 					ac.Location = rt.From
 					origin = rt.From
 					dest = rt.To
@@ -1547,21 +4665,17 @@ func advanceTickLocked() {
 					}
 				}
 
-				// Start Flight
+				// Plan the leg
 				ac.FlightPlan.Origin = origin
 				ac.FlightPlan.Dest = dest
-				
+
 				fromAp := airportsByIdent[origin]
 				toAp := airportsByIdent[dest]
 				dist := haversine(fromAp.Latitude, fromAp.Longitude, toAp.Latitude, toAp.Longitude)
-				
-				flightMins := int((dist / ac.CruiseKmh) * 60)
-				if flightMins < 10 {
-					flightMins = 10
-				}
 
-				ac.State = "Flying"
-				ac.Timer = flightMins
+				ac.Phases = flightops.ComputePhases(dist, ac.CruiseKmh, ac.Turnaround)
+				ac.State = flightops.Boarding
+				ac.Timer = ac.Phases.Duration(flightops.Boarding)
 
 				// Revenue Logic
 				randomLoad := rt.LoadFactor * (0.9 + rng.Float64()*0.2)
@@ -1572,25 +4686,57 @@ func advanceTickLocked() {
 				numLegs := 1
 				if rt.Via != "" { numLegs = 2 }
 				legPrice := rt.UserPrice / float64(numLegs)
-				
-				revenue := float64(passengers) * legPrice
-				cost := (dist * ac.FuelCost) + 500 + toAp.LandingFee
+
+				subsidyBonus := applySubsidyLocked(origin, dest, passengers)
+				revenue := float64(passengers) * legPrice * subsidyBonus
+				fuelAtSpot := dist * ac.FuelCost * state.FuelMarket.Price
+				fuelEffective := dist * ac.FuelCost * fuelMultiplier
+				cost := fuelEffective + 500 + toAp.LandingFee
 
 				totalRevenue += revenue
 				totalCost += cost
-				
+				totalFuelAtSpot += fuelAtSpot
+				totalFuelEffective += fuelEffective
+
 				rt.LastTickRevenue = revenue
 				rt.LastTickLoad = float64(passengers) / float64(ac.Seats)
 				rt.ProfitPerTick = revenue - cost
+
+				cargoRevenue, cargoCost := applyCargoLegLocked(rt, origin, dest, dist)
+				totalRevenue += cargoRevenue
+				totalCost += cargoCost
+				rt.LastTickCargoRevenue = cargoRevenue
+				rt.CargoProfitPerTick = cargoRevenue - cargoCost
+
+				flightRecords.Add(analytics.Record{
+					Tick:        state.Tick,
+					AircraftID:  ac.ID,
+					RouteID:     rt.ID,
+					From:        origin,
+					To:          dest,
+					DestCountry: toAp.Country,
+					Passengers:  passengers,
+					LoadFactor:  rt.LastTickLoad,
+					Revenue:     revenue + cargoRevenue,
+					Cost:        cost + cargoCost,
+					OnTime:      !rt.CurfewBlocked,
+				})
+
+				if rt.PriceDiscoveryActive {
+					priceDiscoveryRouteLocked(rt, passengers)
+				} else {
+					autoManageRouteLocked(rt)
+					yieldManageRouteLocked(rt, fromAp, toAp)
+				}
 			}
 
-		case "Idle":
-			rt := findRouteForAc(ac.ID)
-			if rt != nil {
+		case flightops.Idle:
+			rt := findRouteForAcLocked(ac)
+			if rt != nil && !rt.Suspended {
 				if ac.Location == "" {
 					ac.Location = rt.From
 				}
-				ac.State = "Turnaround"
+				ac.State = flightops.Turnaround
 				ac.Timer = ac.Turnaround
 			}
 		}
@@ -1603,16 +4749,26 @@ func advanceTickLocked() {
 		}
 	}
 	state.Cash += totalRevenue - totalCost - leaseCost
-	
+	applyFuelHedgeSavingsLocked(totalFuelAtSpot, totalFuelEffective)
+
 	advanceFleetTimersLocked()
 	applyMaintenanceWearLocked()
+	accrueLostProfitLocked()
+	expireSubsidiesLocked()
 	state.Tick++
-	if state.Tick%6 == 0 { 
+	if state.Tick%6 == 0 {
 		recalcUtilizationLocked()
 	}
+	if state.Tick%subsidyOfferIntervalTicks == 0 {
+		offerSubsidiesLocked()
+	}
+	if state.Tick%disruptionCheckIntervalTicks == 0 {
+		maybeGenerateDisruptionLocked()
+	}
 	if err := saveState(saveFilePath, &state); err != nil {
 		log.Printf("failed to save state: %v", err)
 	}
+	notifyLocked()
 }
 
 func intervalForSpeed(speed int) time.Duration {
@@ -4,11 +4,21 @@ import (
 	"testing"
 )
 
+// uniformSlotsPerHour returns a 24-bucket slot histogram with the same
+// limit in every hour, for tests that don't care about bank placement.
+func uniformSlotsPerHour(n int) [24]int {
+	var hours [24]int
+	for i := range hours {
+		hours[i] = n
+	}
+	return hours
+}
+
 // set up minimal airport data and fleet for tests
 func setupTestAirports() {
 	store = &AirportStore{Airports: []Airport{
-		{ID: "1", Ident: "AAA", Type: "large_airport", Name: "A", Latitude: 0, Longitude: 0, RunwayM: 3200, SlotsPerDay: 200, LandingFee: 1000},
-		{ID: "2", Ident: "BBB", Type: "large_airport", Name: "B", Latitude: 0, Longitude: 10, RunwayM: 3200, SlotsPerDay: 200, LandingFee: 1000},
+		{ID: "1", Ident: "AAA", Type: "large_airport", Name: "A", Latitude: 0, Longitude: 0, RunwayM: 3200, SlotsPerHour: uniformSlotsPerHour(200), LandingFee: 1000},
+		{ID: "2", Ident: "BBB", Type: "large_airport", Name: "B", Latitude: 0, Longitude: 10, RunwayM: 3200, SlotsPerHour: uniformSlotsPerHour(200), LandingFee: 1000},
 	}}
 	airportsByIdent = map[string]Airport{
 		"AAA": store.Airports[0],
@@ -30,9 +40,9 @@ func setupTestAirports() {
 
 func TestBuildRouteEconomicsIncludesFeesAndProfit(t *testing.T) {
 	setupTestAirports()
-	state = GameState{Fleet: seedFleet()}
+	state = GameState{Fleet: seedFleet(), FuelMarket: defaultFuelMarket()}
 
-	rt, err := buildRoute("AAA", "BBB", "", "A320", 2, 0)
+	rt, err := buildRoute("AAA", "BBB", "", "A320", 2, 0, "")
 	if err != nil {
 		t.Fatalf("buildRoute returned error: %v", err)
 	}
@@ -64,8 +74,8 @@ func TestValidateCapacityAndSlots(t *testing.T) {
 	}
 
 	// Now check slot limit using tight slots
-	store.Airports[0].SlotsPerDay = 2
-	store.Airports[1].SlotsPerDay = 2
+	store.Airports[0].SlotsPerHour = uniformSlotsPerHour(2)
+	store.Airports[1].SlotsPerHour = uniformSlotsPerHour(2)
 	airportsByIdent["AAA"] = store.Airports[0]
 	airportsByIdent["BBB"] = store.Airports[1]
 	state.Routes = []Route{
@@ -77,6 +87,267 @@ func TestValidateCapacityAndSlots(t *testing.T) {
 	}
 }
 
+func TestValidateCapacityHonorsPerHourDepartureHours(t *testing.T) {
+	setupTestAirports()
+	store.Airports[0].SlotsPerHour = uniformSlotsPerHour(1)
+	store.Airports[1].SlotsPerHour = uniformSlotsPerHour(1)
+	airportsByIdent["AAA"] = store.Airports[0]
+	airportsByIdent["BBB"] = store.Airports[1]
+
+	// Seeded at hour 12 so it doesn't itself compete for the hour-0/hour-7
+	// slots the cases below exercise.
+	state = GameState{
+		Fleet: []OwnedCraft{{TemplateID: "A320", Status: "active"}},
+		Routes: []Route{
+			{AircraftID: "A320", BlockMinutes: 100, FrequencyPerDay: 1, From: "AAA", To: "BBB", DepartureBank: 12},
+		},
+	}
+
+	// Bunching both frequencies into hour 0 exceeds the 1-slot/hour limit.
+	bunched := Route{AircraftID: "A320", BlockMinutes: 100, FrequencyPerDay: 2, From: "AAA", To: "BBB", DepartureBank: 0}
+	if err := validateCapacityLocked(bunched); err == nil {
+		t.Fatalf("expected slot limit error when both departures share hour 0")
+	}
+
+	// Spreading them across hours 0 and 7 via DepartureHours fits within the
+	// per-hour limit even though FrequencyPerDay is unchanged.
+	spread := Route{AircraftID: "A320", BlockMinutes: 100, FrequencyPerDay: 2, From: "AAA", To: "BBB", DepartureHours: []int{0, 7}}
+	if err := validateCapacityLocked(spread); err != nil {
+		t.Fatalf("expected spreading departures across hours to fit the slot limit, got %v", err)
+	}
+}
+
+func TestPeakedSlotsPerHourWeightsCommuteWindows(t *testing.T) {
+	hours := peakedSlotsPerHour(240)
+	if hours[8] <= hours[2] {
+		t.Fatalf("expected the morning peak hour to get more slots than an off-peak hour, got peak=%d offpeak=%d", hours[8], hours[2])
+	}
+}
+
+func TestLandingFeeAtHourSurchargesPeakWindows(t *testing.T) {
+	ap := Airport{LandingFee: 1000}
+	if got, want := landingFeeAtHour(ap, 8), 1000*peakLandingFeeMultiplier; got != want {
+		t.Fatalf("expected peak-hour fee %.2f, got %.2f", want, got)
+	}
+	if got := landingFeeAtHour(ap, 2); got != 1000 {
+		t.Fatalf("expected off-peak fee to be unchanged, got %.2f", got)
+	}
+}
+
+func TestValidateCapacityRejectsFullClosureDisruption(t *testing.T) {
+	setupTestAirports()
+	state = GameState{
+		Fleet: []OwnedCraft{{TemplateID: "A320", Status: "active"}},
+		Alerts: []Alert{
+			{Kind: DisruptionWeatherClosure, Airports: []string{"BBB"}, StartTick: 0, EndTick: 100, SlotMultiplier: 0},
+		},
+	}
+	route := Route{AircraftID: "A320", BlockMinutes: 200, FrequencyPerDay: 1, From: "AAA", To: "BBB"}
+	if err := validateCapacityLocked(route); err == nil {
+		t.Fatalf("expected a disruption closure to block the route, got nil")
+	}
+
+	state.Tick = 200 // past the alert's EndTick
+	if err := validateCapacityLocked(route); err != nil {
+		t.Fatalf("expected the route to validate once the disruption expired, got %v", err)
+	}
+}
+
+func TestCurfewHoursWithAlertsAddsOverrideOnTopOfBaseCurfew(t *testing.T) {
+	setupTestAirports()
+	state = GameState{Alerts: []Alert{
+		{Kind: DisruptionStrike, Airports: []string{"AAA"}, StartTick: 0, EndTick: 100, CurfewOverride: &HourRange{Start: 22, End: 2}},
+	}}
+
+	hours := curfewHoursWithAlertsLocked("AAA", [24]bool{})
+	for _, h := range []int{22, 23, 0, 1} {
+		if !hours[h] {
+			t.Fatalf("expected hour %d closed by the strike override, got open", h)
+		}
+	}
+	if hours[12] {
+		t.Fatalf("expected hour 12 to remain open")
+	}
+}
+
+func TestApplyCargoLegLockedSellsAgainstOriginSupply(t *testing.T) {
+	setupTestAirports()
+	state = GameState{CommodityMarket: defaultCommodityMarket()}
+
+	rt := &Route{CargoCommodity: "mail", CargoHoldKg: 5000}
+	revenue, cost := applyCargoLegLocked(rt, "AAA", "BBB", 1000)
+	if revenue <= 0 || cost <= 0 {
+		t.Fatalf("expected positive revenue and cost, got revenue=%.2f cost=%.2f", revenue, cost)
+	}
+
+	origin := state.CommodityMarket.Spots["AAA"]["mail"]
+	dest := state.CommodityMarket.Spots["BBB"]["mail"]
+	if origin.Supply != baseCommoditySupplyKg-5000 {
+		t.Fatalf("expected origin supply drawn down by 5000kg, got %.2f", origin.Supply)
+	}
+	if dest.Supply != baseCommoditySupplyKg+5000 {
+		t.Fatalf("expected destination supply credited 5000kg, got %.2f", dest.Supply)
+	}
+}
+
+func TestApplySubsidyLockedAwardsAndAppliesBonus(t *testing.T) {
+	state = GameState{
+		Subsidies: []Subsidy{
+			{From: "AAA", To: "BBB", TargetVolume: 100, BonusPct: 0.25, BonusDurationTicks: 1000, Status: SubsidyOffered, ExpiresTick: 1000},
+		},
+	}
+
+	if bonus := applySubsidyLocked("AAA", "BBB", 60); bonus != 1.0 {
+		t.Fatalf("expected no bonus before the target volume is reached, got %.2f", bonus)
+	}
+	if state.Subsidies[0].Status != SubsidyOffered {
+		t.Fatalf("expected subsidy to remain offered at 60/100, got %s", state.Subsidies[0].Status)
+	}
+
+	bonus := applySubsidyLocked("AAA", "BBB", 60)
+	if state.Subsidies[0].Status != SubsidyAwarded {
+		t.Fatalf("expected subsidy to be awarded once volume crosses 100, got %s", state.Subsidies[0].Status)
+	}
+	if bonus != 1.25 {
+		t.Fatalf("expected a 1.25x bonus on the awarding leg, got %.2f", bonus)
+	}
+}
+
+func TestYieldATRAveragesSubWindowRanges(t *testing.T) {
+	// Two sub-windows of size 3: [0.5,0.9,0.6] range 0.4, [0.9,0.6,0.8] range 0.3.
+	atr := yieldATR([]float64{0.5, 0.9, 0.6, 0.8}, 3)
+	if got, want := atr, 0.35; got < want-1e-9 || got > want+1e-9 {
+		t.Fatalf("expected ATR 0.35, got %.4f", got)
+	}
+	if got := yieldATR([]float64{0.5, 0.9}, 3); got != 0 {
+		t.Fatalf("expected 0 ATR with fewer samples than the sub-window, got %.4f", got)
+	}
+}
+
+func TestYieldManageRouteLockedRaisesPriceAboveActivationBand(t *testing.T) {
+	setupTestAirports()
+	state = GameState{Fleet: seedFleet()}
+
+	rt := &Route{
+		AircraftID:              "A320",
+		FrequencyPerDay:         2,
+		UserPrice:               200,
+		YieldManage:             true,
+		TrailingActivationRatio: []float64{0.85},
+		YieldLoadWindow:         []float64{0.9, 0.92, 0.91},
+		LastTickLoad:            0.93,
+	}
+	yieldManageRouteLocked(rt, airportsByIdent["AAA"], airportsByIdent["BBB"])
+
+	if rt.UserPrice <= 200 {
+		t.Fatalf("expected price to rise above the activation band, got %.2f", rt.UserPrice)
+	}
+}
+
+func TestSuggestFaresOrdersEconomyBalancedPremiumByPrice(t *testing.T) {
+	setupTestAirports()
+	state = GameState{Fleet: seedFleet(), FuelMarket: defaultFuelMarket()}
+	ac, err := findAircraft("A320")
+	if err != nil {
+		t.Fatalf("findAircraft: %v", err)
+	}
+
+	sugg := suggestFares(airportsByIdent["AAA"], airportsByIdent["BBB"], ac, 2, 0)
+
+	if sugg.Economy.Price > sugg.Balanced.Price {
+		t.Fatalf("expected economy price <= balanced price, got economy=%.2f balanced=%.2f", sugg.Economy.Price, sugg.Balanced.Price)
+	}
+	if sugg.Balanced.Price > sugg.Premium.Price {
+		t.Fatalf("expected balanced price <= premium price, got balanced=%.2f premium=%.2f", sugg.Balanced.Price, sugg.Premium.Price)
+	}
+	if sugg.Economy.LoadFactor < 0.9 {
+		t.Fatalf("expected economy load factor >= 0.9, got %.2f", sugg.Economy.LoadFactor)
+	}
+}
+
+func TestFitDemandCurveRecoversKnownLine(t *testing.T) {
+	prices := []float64{100, 150, 200, 250}
+	sold := []float64{80, 65, 50, 35} // sold = 110 - 0.3*price, exactly
+	curve := fitDemandCurve(prices, sold)
+
+	if got, want := curve.A, 110.0; got < want-1e-6 || got > want+1e-6 {
+		t.Fatalf("expected A=%.4f, got %.4f", want, got)
+	}
+	if got, want := curve.B, -0.3; got < want-1e-6 || got > want+1e-6 {
+		t.Fatalf("expected B=%.4f, got %.4f", want, got)
+	}
+}
+
+func TestRevenueMaximizingPriceClampsToProbedRange(t *testing.T) {
+	levels := []float64{100, 150, 200, 250}
+
+	// sold = 110 - 0.3*price -> revenue vertex at price = -110/(2*-0.3) ~= 183.3, inside range.
+	inRange := revenueMaximizingPrice(DemandCurve{A: 110, B: -0.3}, levels)
+	if inRange < 180 || inRange > 186 {
+		t.Fatalf("expected vertex price near 183.3, got %.2f", inRange)
+	}
+
+	// A non-decreasing curve has no interior maximum; falls back to the top level.
+	if got := revenueMaximizingPrice(DemandCurve{A: 50, B: 0.1}, levels); got != 250 {
+		t.Fatalf("expected fallback to highest level 250, got %.2f", got)
+	}
+}
+
+func TestPriceDiscoveryRouteLockedFitsCurveAndAppliesFare(t *testing.T) {
+	setupTestAirports()
+	state = GameState{Fleet: seedFleet()}
+
+	rt := &Route{ID: "r1", From: "AAA", To: "BBB", AircraftID: "A320", FrequencyPerDay: 1, UserPrice: 200}
+	if err := runPriceDiscoveryProbeLocked(rt, 0.2, 3, 2); err != nil {
+		t.Fatalf("runPriceDiscoveryProbeLocked: %v", err)
+	}
+	if got, want := rt.UserPrice, rt.PriceDiscoveryLevels[0]; got != want {
+		t.Fatalf("expected probe to start at the first level %.2f, got %.2f", want, got)
+	}
+
+	soldByStep := []int{120, 100, 80} // demand falling as the probe climbs price
+	for step, sold := range soldByStep {
+		for i := 0; i < 2; i++ {
+			priceDiscoveryRouteLocked(rt, sold)
+		}
+		if step < len(soldByStep)-1 && !rt.PriceDiscoveryActive {
+			t.Fatalf("expected probe still active after step %d", step)
+		}
+	}
+
+	if rt.PriceDiscoveryActive {
+		t.Fatalf("expected probe to finish after all steps")
+	}
+	curve, ok := state.MarketDemandCurves[marketKey("AAA", "BBB")]
+	if !ok {
+		t.Fatalf("expected a persisted demand curve for the market")
+	}
+	if curve.Samples != 3 {
+		t.Fatalf("expected 3 samples, got %d", curve.Samples)
+	}
+	if curve.B >= 0 {
+		t.Fatalf("expected a negative slope from falling sold-by-price samples, got %.4f", curve.B)
+	}
+}
+
+func TestDemandEstimateWithOptsPrefersEmpiricalCurveOnceSampled(t *testing.T) {
+	setupTestAirports()
+	state = GameState{
+		MarketDemandCurves: map[string]DemandCurve{
+			marketKey("AAA", "BBB"): {A: 500, B: -1, Samples: minDemandCurveSamples},
+		},
+	}
+	ac, err := findAircraft("A320")
+	if err != nil {
+		t.Fatalf("findAircraft: %v", err)
+	}
+
+	got := demandEstimateWithOpts(airportsByIdent["AAA"], airportsByIdent["BBB"], ac, 1, demandOptions{Price: 300, MarketKey: marketKey("AAA", "BBB")})
+	if want := 200; got != want { // 500 - 1*300 = 200
+		t.Fatalf("expected empirical curve estimate %d, got %d", want, got)
+	}
+}
+
 func TestMarketUniqueness(t *testing.T) {
 	setupTestAirports()
 	state = GameState{
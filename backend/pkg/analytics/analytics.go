@@ -0,0 +1,151 @@
+// Package analytics maintains an in-memory ring buffer of per-leg flight
+// records and aggregates them into grouped summary reports, so players can
+// answer questions like "revenue by hour of day" or "load factor by
+// aircraft" without scanning the flat event stream by hand.
+package analytics
+
+import (
+	"sort"
+	"strconv"
+)
+
+// Record is one completed leg, logged once per departure.
+type Record struct {
+	Tick        int
+	AircraftID  string
+	RouteID     string
+	From        string
+	To          string
+	DestCountry string
+	Passengers  int
+	LoadFactor  float64
+	Revenue     float64
+	Cost        float64
+	OnTime      bool
+}
+
+// GroupBy names a supported SummaryReport dimension.
+type GroupBy string
+
+const (
+	ByHourOfDay   GroupBy = "hour"
+	ByDay         GroupBy = "day"
+	ByAircraft    GroupBy = "aircraft"
+	ByOrigin      GroupBy = "origin"
+	ByDestCountry GroupBy = "dest_country"
+	ByRoute       GroupBy = "route"
+)
+
+const (
+	ticksPerHour = 60
+	ticksPerDay  = 24 * ticksPerHour
+)
+
+func keyFor(groupBy GroupBy, r Record) string {
+	switch groupBy {
+	case ByHourOfDay:
+		return strconv.Itoa((r.Tick / ticksPerHour) % 24)
+	case ByDay:
+		return strconv.Itoa(r.Tick / ticksPerDay)
+	case ByAircraft:
+		return r.AircraftID
+	case ByOrigin:
+		return r.From
+	case ByDestCountry:
+		return r.DestCountry
+	case ByRoute:
+		return r.RouteID
+	default:
+		return ""
+	}
+}
+
+// Bucket is one group's aggregated totals in a SummaryReport.
+type Bucket struct {
+	Key        string  `json:"key"`
+	Flights    int     `json:"flights"`
+	Passengers int     `json:"passengers"`
+	Revenue    float64 `json:"revenue"`
+	Cost       float64 `json:"cost"`
+	Profit     float64 `json:"profit"`
+	LoadFactor float64 `json:"load_factor"` // average across Flights
+	OnTimePct  float64 `json:"on_time_pct"`
+}
+
+// Buffer is a fixed-capacity ring buffer of Records; once full, the oldest
+// Record is overwritten first.
+type Buffer struct {
+	records []Record
+	next    int
+	full    bool
+}
+
+// NewBuffer allocates a ring buffer holding up to capacity Records.
+func NewBuffer(capacity int) *Buffer {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &Buffer{records: make([]Record, capacity)}
+}
+
+// Add appends r, overwriting the oldest entry once the buffer is full.
+func (b *Buffer) Add(r Record) {
+	b.records[b.next] = r
+	b.next = (b.next + 1) % len(b.records)
+	if b.next == 0 {
+		b.full = true
+	}
+}
+
+// All returns every retained Record, oldest first.
+func (b *Buffer) All() []Record {
+	if !b.full {
+		out := make([]Record, b.next)
+		copy(out, b.records[:b.next])
+		return out
+	}
+	out := make([]Record, len(b.records))
+	copy(out, b.records[b.next:])
+	copy(out[len(b.records)-b.next:], b.records[:b.next])
+	return out
+}
+
+// SummaryReport aggregates every retained Record with start <= Tick <= end
+// into buckets keyed by groupBy, sorted by descending profit.
+func (b *Buffer) SummaryReport(start, end int, groupBy GroupBy) []Bucket {
+	byKey := make(map[string]*Bucket)
+	var order []string
+	for _, r := range b.All() {
+		if r.Tick < start || r.Tick > end {
+			continue
+		}
+		key := keyFor(groupBy, r)
+		bucket, ok := byKey[key]
+		if !ok {
+			bucket = &Bucket{Key: key}
+			byKey[key] = bucket
+			order = append(order, key)
+		}
+		bucket.Flights++
+		bucket.Passengers += r.Passengers
+		bucket.Revenue += r.Revenue
+		bucket.Cost += r.Cost
+		bucket.LoadFactor += r.LoadFactor
+		if r.OnTime {
+			bucket.OnTimePct++
+		}
+	}
+
+	out := make([]Bucket, 0, len(order))
+	for _, key := range order {
+		bucket := byKey[key]
+		bucket.Profit = bucket.Revenue - bucket.Cost
+		if bucket.Flights > 0 {
+			bucket.LoadFactor /= float64(bucket.Flights)
+			bucket.OnTimePct /= float64(bucket.Flights)
+		}
+		out = append(out, *bucket)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Profit > out[j].Profit })
+	return out
+}
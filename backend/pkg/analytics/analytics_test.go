@@ -0,0 +1,40 @@
+package analytics
+
+import "testing"
+
+func TestSummaryReportGroupsByRoute(t *testing.T) {
+	b := NewBuffer(10)
+	b.Add(Record{Tick: 0, RouteID: "r1", Passengers: 100, LoadFactor: 0.8, Revenue: 1000, Cost: 400, OnTime: true})
+	b.Add(Record{Tick: 60, RouteID: "r1", Passengers: 90, LoadFactor: 0.7, Revenue: 900, Cost: 400, OnTime: false})
+	b.Add(Record{Tick: 120, RouteID: "r2", Passengers: 50, LoadFactor: 0.5, Revenue: 500, Cost: 450, OnTime: true})
+
+	report := b.SummaryReport(0, 1000, ByRoute)
+	if len(report) != 2 {
+		t.Fatalf("expected 2 buckets, got %d", len(report))
+	}
+	r1 := report[0]
+	if r1.Key != "r1" || r1.Flights != 2 {
+		t.Fatalf("expected r1 to be the top bucket with 2 flights, got %+v", r1)
+	}
+	if r1.Profit != 1100 {
+		t.Fatalf("expected r1 profit 1100, got %.2f", r1.Profit)
+	}
+	if r1.OnTimePct != 0.5 {
+		t.Fatalf("expected r1 on-time pct 0.5, got %.2f", r1.OnTimePct)
+	}
+}
+
+func TestBufferWrapsAtCapacity(t *testing.T) {
+	b := NewBuffer(2)
+	b.Add(Record{Tick: 0, RouteID: "a"})
+	b.Add(Record{Tick: 1, RouteID: "b"})
+	b.Add(Record{Tick: 2, RouteID: "c"})
+
+	all := b.All()
+	if len(all) != 2 {
+		t.Fatalf("expected buffer capped at 2 records, got %d", len(all))
+	}
+	if all[0].RouteID != "b" || all[1].RouteID != "c" {
+		t.Fatalf("expected oldest record dropped, got %+v", all)
+	}
+}
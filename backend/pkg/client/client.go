@@ -0,0 +1,634 @@
+// Package client is a typed Go client for the airline_builder HTTP API
+// described by api/openapi.yaml. Field names and shapes are kept in lockstep
+// with that spec by hand; nothing in the tree generates this package.
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Client talks to a running airline_builder server.
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+// New returns a Client pointed at baseURL, e.g. "http://localhost:4000".
+func New(baseURL string) *Client {
+	return &Client{BaseURL: baseURL, HTTPClient: http.DefaultClient}
+}
+
+type Airport struct {
+	ID           string   `json:"id"`
+	Ident        string   `json:"ident"`
+	Type         string   `json:"type"`
+	Name         string   `json:"name"`
+	Latitude     float64  `json:"lat"`
+	Longitude    float64  `json:"lon"`
+	Country      string   `json:"country"`
+	Region       string   `json:"region"`
+	City         string   `json:"city"`
+	IATA         string   `json:"iata"`
+	ICAO         string   `json:"icao"`
+	RunwayM      int      `json:"runway_m"`
+	SlotsPerHour [24]int  `json:"slots_per_hour"`
+	LandingFee   float64  `json:"landing_fee"`
+	Curfew       bool     `json:"curfew"`
+	CurfewStart  int      `json:"curfew_start_hour"`
+	CurfewEnd    int      `json:"curfew_end_hour"`
+	CurfewHours  [24]bool `json:"curfew_hours"`
+}
+
+type Aircraft struct {
+	ID            string  `json:"id"`
+	Name          string  `json:"name"`
+	Role          string  `json:"role"`
+	RangeKm       float64 `json:"range_km"`
+	Seats         int     `json:"seats"`
+	CruiseKmh     float64 `json:"cruise_kmh"`
+	FuelCost      float64 `json:"fuel_cost_per_km"`
+	TurnaroundMin int     `json:"turnaround_min"`
+}
+
+type Route struct {
+	ID                   string    `json:"id"`
+	From                 string    `json:"from"`
+	To                   string    `json:"to"`
+	Via                  string    `json:"via,omitempty"`
+	AircraftID           string    `json:"aircraft_id"`
+	FrequencyPerDay      int       `json:"frequency_per_day"`
+	DepartureBank        int       `json:"departure_bank"`
+	UserPrice            float64   `json:"user_price"`
+	LoadFactor           float64   `json:"load_factor"`
+	ProfitPerTick        float64   `json:"profit_per_tick"`
+	CurfewBlocked        bool      `json:"curfew_blocked"`
+	AssignedCraftIDs     []string  `json:"assigned_craft_ids,omitempty"`
+	LostProfit           float64   `json:"lost_profit"`
+	LostProfitHistory    []float64 `json:"lost_profit_history,omitempty"`
+	CargoCommodity       string    `json:"cargo_commodity,omitempty"`
+	CargoHoldKg          float64   `json:"cargo_hold_kg,omitempty"`
+	LastTickCargoRevenue float64   `json:"last_tick_cargo_revenue,omitempty"`
+	CargoProfitPerTick   float64   `json:"cargo_profit_per_tick,omitempty"`
+}
+
+type OwnedCraft struct {
+	ID            string  `json:"id"`
+	TemplateID    string  `json:"template_id"`
+	Name          string  `json:"name"`
+	Status        string  `json:"status"`
+	AvailableIn   int     `json:"available_in_ticks"`
+	Utilization   float64 `json:"utilization_pct"`
+	Condition     float64 `json:"condition_pct"`
+	OwnershipType string  `json:"ownership_type,omitempty"`
+	State         string  `json:"state"`
+	Location      string  `json:"location"`
+}
+
+type TrackPoint struct {
+	Lat      float64 `json:"lat"`
+	Lon      float64 `json:"lon"`
+	Fraction float64 `json:"fraction"`
+}
+
+type FlightTrack struct {
+	AircraftID string       `json:"aircraft_id"`
+	State      string       `json:"state"`
+	From       string       `json:"from"`
+	To         string       `json:"to"`
+	Fraction   float64      `json:"fraction"`
+	Position   TrackPoint   `json:"position"`
+	Track      []TrackPoint `json:"track,omitempty"`
+}
+
+type GameState struct {
+	Cash           float64      `json:"cash"`
+	Routes         []Route      `json:"routes"`
+	Fleet          []OwnedCraft `json:"fleet"`
+	Tick           int          `json:"tick"`
+	IsRunning      bool         `json:"is_running"`
+	Speed          int          `json:"speed"`
+	LostProfitTick float64      `json:"lost_profit_tick"`
+	FuelMarket     FuelMarket   `json:"fuel_market"`
+}
+
+type FuelHedge struct {
+	Mode            string  `json:"mode"`
+	LockedFraction  float64 `json:"locked_fraction"`
+	LockedPrice     float64 `json:"locked_price"`
+	Premium         float64 `json:"premium"`
+	ExpiresAtTick   int     `json:"expires_at_tick"`
+	RealizedSavings float64 `json:"realized_savings"`
+}
+
+type FuelMarket struct {
+	Price  float64     `json:"price"`
+	Theta  float64     `json:"theta"`
+	Mu     float64     `json:"mu"`
+	Sigma  float64     `json:"sigma"`
+	Hedges []FuelHedge `json:"hedges,omitempty"`
+}
+
+type FuelHedgeRequest struct {
+	Mode   string `json:"mode"`
+	Months int    `json:"months"`
+}
+
+type CreateRouteRequest struct {
+	From            string  `json:"from"`
+	To              string  `json:"to"`
+	Via             string  `json:"via,omitempty"`
+	AircraftID      string  `json:"aircraft_id"`
+	FrequencyPerDay int     `json:"frequency_per_day"`
+	OneWay          bool    `json:"one_way,omitempty"`
+	UserPrice       float64 `json:"user_price,omitempty"`
+	CargoCommodity  string  `json:"cargo_commodity,omitempty"`
+}
+
+type RouteAnalysisRequest struct {
+	Origin        string   `json:"origin"`
+	Dest          string   `json:"dest"`
+	Via           string   `json:"via,omitempty"`
+	AircraftTypes []string `json:"aircraft_types"`
+}
+
+type RouteAnalysisResult struct {
+	AircraftType string  `json:"aircraft_type"`
+	Frequency    float64 `json:"frequency"`
+	LoadFactor   float64 `json:"load_factor"`
+	DailyProfit  float64 `json:"daily_profit"`
+	RoiScore     float64 `json:"roi_score"`
+	Valid        bool    `json:"valid"`
+	Error        string  `json:"error,omitempty"`
+}
+
+type ScheduleCandidate struct {
+	From            string  `json:"from"`
+	To              string  `json:"to"`
+	AircraftID      string  `json:"aircraft_id"`
+	FrequencyPerDay int     `json:"frequency_per_day,omitempty"`
+	UserPrice       float64 `json:"user_price,omitempty"`
+}
+
+type ScheduleRequest struct {
+	Candidates []ScheduleCandidate `json:"candidates"`
+}
+
+type ScheduleAssignment struct {
+	AircraftID   string `json:"aircraft_id"`
+	RouteID      string `json:"route_id"`
+	DepartureMin int    `json:"departure_min"`
+	ArrivalMin   int    `json:"arrival_min"`
+}
+
+type ScheduleUnschedulable struct {
+	AircraftID string `json:"aircraft_id"`
+	RouteID    string `json:"route_id"`
+	Reason     string `json:"reason"`
+}
+
+type ScheduleResponse struct {
+	Assignments   []ScheduleAssignment    `json:"assignments"`
+	Unschedulable []ScheduleUnschedulable `json:"unschedulable"`
+}
+
+type PlanRoutesRequest struct {
+	Start           string   `json:"start"`
+	AircraftID      string   `json:"aircraft_id"`
+	Candidates      []string `json:"candidates,omitempty"`
+	MustVisit       []string `json:"must_visit,omitempty"`
+	AcceptableEnds  []string `json:"acceptable_ends,omitempty"`
+	MaxHops         int      `json:"max_hops,omitempty"`
+	TopN            int      `json:"top_n,omitempty"`
+	FrequencyPerDay int      `json:"frequency_per_day,omitempty"`
+	UserPrice       float64  `json:"user_price,omitempty"`
+}
+
+type PlannedItinerary struct {
+	Airports          []string `json:"airports"`
+	TotalProfit       float64  `json:"total_profit"`
+	TotalBlockMinutes float64  `json:"total_block_minutes"`
+}
+
+type FleetOptimizeRequest struct {
+	Candidates   []string `json:"candidates,omitempty"`
+	HorizonTicks int      `json:"horizon_ticks,omitempty"`
+	CashBudget   float64  `json:"cash_budget,omitempty"`
+	FleetIDs     []string `json:"fleet_ids,omitempty"`
+	MustVisit    []string `json:"must_visit,omitempty"`
+	UserPrice    float64  `json:"user_price,omitempty"`
+}
+
+type FleetSuggestion struct {
+	AircraftID    string   `json:"aircraft_id"`
+	Airports      []string `json:"airports"`
+	TotalProfit   float64  `json:"total_profit"`
+	TicksUsed     int      `json:"ticks_used"`
+	ProfitPerTick float64  `json:"profit_per_tick"`
+	Utilization   float64  `json:"utilization"`
+}
+
+type NetworkOptimizeRequest struct {
+	Candidates    []string `json:"candidates,omitempty"`
+	AircraftTypes []string `json:"aircraft_types,omitempty"`
+	Frequencies   []int    `json:"frequencies,omitempty"`
+	CashBudget    float64  `json:"cash_budget,omitempty"`
+	HorizonDays   int      `json:"horizon_days,omitempty"`
+	UserPrice     float64  `json:"user_price,omitempty"`
+}
+
+type NetworkAddition struct {
+	AircraftType string  `json:"aircraft_type"`
+	From         string  `json:"from"`
+	To           string  `json:"to"`
+	Frequency    int     `json:"frequency"`
+	Profit       float64 `json:"profit"`
+}
+
+type NetworkPlanResponse struct {
+	Additions      []NetworkAddition `json:"additions"`
+	DailyNetCash   float64           `json:"daily_net_cash"`
+	HorizonNetCash float64           `json:"horizon_net_cash"`
+}
+
+type SuggestFaresRequest struct {
+	From                string  `json:"from"`
+	To                  string  `json:"to"`
+	AircraftID          string  `json:"aircraft_id"`
+	FrequencyPerDay     int     `json:"frequency_per_day,omitempty"`
+	ElasticityThreshold float64 `json:"elasticity_threshold,omitempty"`
+}
+
+type FareQuote struct {
+	Price        float64 `json:"price"`
+	Revenue      float64 `json:"revenue"`
+	Cost         float64 `json:"cost"`
+	LoadFactor   float64 `json:"load_factor"`
+	PaybackTicks float64 `json:"payback_ticks,omitempty"`
+}
+
+type FareSuggestion struct {
+	Economy  FareQuote `json:"economy"`
+	Balanced FareQuote `json:"balanced"`
+	Premium  FareQuote `json:"premium"`
+}
+
+type RouteLostProfit struct {
+	RouteID    string    `json:"route_id"`
+	LostProfit float64   `json:"lost_profit"`
+	History    []float64 `json:"history,omitempty"`
+}
+
+type CongestionHourBucket struct {
+	Hour         int            `json:"hour"`
+	Departures   int            `json:"departures"`
+	SlotLimit    int            `json:"slot_limit"`
+	CurfewClosed bool           `json:"curfew_closed"`
+	ByAircraft   map[string]int `json:"by_aircraft"`
+	ByRoute      map[string]int `json:"by_route"`
+}
+
+type AirportCongestion struct {
+	Ident string                 `json:"ident"`
+	Hours []CongestionHourBucket `json:"hours"`
+}
+
+type Commodity struct {
+	ID            string  `json:"id"`
+	Name          string  `json:"name"`
+	HaulCostPerKg float64 `json:"haul_cost_per_kg_km"`
+	BasePrice     float64 `json:"base_price"`
+	Theta         float64 `json:"theta"`
+	Sigma         float64 `json:"sigma"`
+}
+
+type CommoditySpotView struct {
+	CommodityID string  `json:"commodity_id"`
+	Price       float64 `json:"price"`
+	Supply      float64 `json:"supply"`
+}
+
+type AirportCommodityPrices struct {
+	Ident string              `json:"ident"`
+	Spots []CommoditySpotView `json:"spots"`
+}
+
+type RouteCargoProfit struct {
+	RouteID              string  `json:"route_id"`
+	CargoCommodity       string  `json:"cargo_commodity"`
+	CargoHoldKg          float64 `json:"cargo_hold_kg"`
+	LastTickCargoRevenue float64 `json:"last_tick_cargo_revenue"`
+	CargoProfitPerTick   float64 `json:"cargo_profit_per_tick"`
+}
+
+type Subsidy struct {
+	ID                 string  `json:"id"`
+	From               string  `json:"from"`
+	To                 string  `json:"to"`
+	TargetVolume       int     `json:"target_volume"`
+	AccumulatedVolume  int     `json:"accumulated_volume"`
+	WindowTicks        int     `json:"window_ticks"`
+	BonusPct           float64 `json:"bonus_pct"`
+	BonusDurationTicks int     `json:"bonus_duration_ticks"`
+	Status             string  `json:"status"`
+	OfferedTick        int     `json:"offered_tick"`
+	AwardedTick        int     `json:"awarded_tick,omitempty"`
+	ExpiresTick        int     `json:"expires_tick"`
+}
+
+type HourRange struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+type DisruptionAlert struct {
+	ID             string     `json:"id"`
+	Kind           string     `json:"kind"`
+	Airports       []string   `json:"airports"`
+	StartTick      int        `json:"start_tick"`
+	EndTick        int        `json:"end_tick"`
+	SlotMultiplier float64    `json:"slot_multiplier"`
+	CurfewOverride *HourRange `json:"curfew_override,omitempty"`
+}
+
+type AnalyticsBucket struct {
+	Key        string  `json:"key"`
+	Flights    int     `json:"flights"`
+	Passengers int     `json:"passengers"`
+	Revenue    float64 `json:"revenue"`
+	Cost       float64 `json:"cost"`
+	Profit     float64 `json:"profit"`
+	LoadFactor float64 `json:"load_factor"`
+	OnTimePct  float64 `json:"on_time_pct"`
+}
+
+type GTFSRTPosition struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+type GTFSRTVehiclePosition struct {
+	VehicleID     string         `json:"vehicle_id"`
+	TripID        string         `json:"trip_id"`
+	CurrentStopID string         `json:"current_stop_id"`
+	Position      GTFSRTPosition `json:"position"`
+	Timestamp     int64          `json:"timestamp"`
+}
+
+type GTFSRTStopTimeUpdate struct {
+	StopID      string `json:"stop_id"`
+	ArrivalTime int64  `json:"arrival_time"`
+}
+
+type GTFSRTTripUpdate struct {
+	TripID          string                 `json:"trip_id"`
+	VehicleID       string                 `json:"vehicle_id"`
+	StopTimeUpdates []GTFSRTStopTimeUpdate `json:"stop_time_update"`
+}
+
+type GTFSRTAlert struct {
+	InformedTripID string `json:"informed_trip_id"`
+	Cause          string `json:"cause"`
+	Effect         string `json:"effect"`
+	HeaderText     string `json:"header_text"`
+}
+
+type GTFSRTFeedEntity struct {
+	ID              string                 `json:"id"`
+	VehiclePosition *GTFSRTVehiclePosition `json:"vehicle,omitempty"`
+	TripUpdate      *GTFSRTTripUpdate      `json:"trip_update,omitempty"`
+	Alert           *GTFSRTAlert           `json:"alert,omitempty"`
+}
+
+type GTFSRTFeedMessage struct {
+	Header struct {
+		GTFSRealtimeVersion string `json:"gtfs_realtime_version"`
+		Timestamp           int64  `json:"timestamp"`
+	} `json:"header"`
+	Entities []GTFSRTFeedEntity `json:"entity"`
+}
+
+// Airports fetches the airport list, optionally filtered by tier ("all", "large", "medium", "small").
+func (c *Client) Airports(tier string) ([]Airport, error) {
+	path := "/airports"
+	if tier != "" {
+		path += "?tier=" + tier
+	}
+	var out []Airport
+	return out, c.get(path, &out)
+}
+
+// AircraftTemplates fetches the aircraft catalog.
+func (c *Client) AircraftTemplates() ([]Aircraft, error) {
+	var out []Aircraft
+	return out, c.get("/aircraft/templates", &out)
+}
+
+// State fetches the current game state.
+func (c *Client) State() (GameState, error) {
+	var out GameState
+	return out, c.get("/state", &out)
+}
+
+// CreateRoute builds and registers a new route.
+func (c *Client) CreateRoute(req CreateRouteRequest) (Route, error) {
+	var out Route
+	return out, c.post("/routes", req, &out)
+}
+
+// Tick advances the simulation by one tick and returns the resulting state.
+func (c *Client) Tick() (GameState, error) {
+	var out GameState
+	return out, c.post("/tick", nil, &out)
+}
+
+// AnalyzeRoute estimates frequency, load, profit, and ROI per aircraft type.
+func (c *Client) AnalyzeRoute(req RouteAnalysisRequest) ([]RouteAnalysisResult, error) {
+	var out []RouteAnalysisResult
+	return out, c.post("/analysis/route", req, &out)
+}
+
+// FleetTrack fetches an owned aircraft's current phase and an interpolated
+// great-circle position track for the leg it's flying.
+func (c *Client) FleetTrack(ownedID string) (FlightTrack, error) {
+	var out FlightTrack
+	return out, c.get("/fleet/"+ownedID+"/track", &out)
+}
+
+// AnalyzeSchedule fits candidate rotations into curfew-clear minute-of-day
+// departure windows, greedily taking the highest-profit rotation first; the
+// resulting total daily profit is not guaranteed to be the maximum.
+func (c *Client) AnalyzeSchedule(req ScheduleRequest) (ScheduleResponse, error) {
+	var out ScheduleResponse
+	return out, c.post("/analysis/schedule", req, &out)
+}
+
+// PlanBestRoutes finds the topN most profitable multi-leg itineraries from a
+// starting airport, generalizing a single from/via/to hop into an arbitrary
+// chain.
+func (c *Client) PlanBestRoutes(req PlanRoutesRequest) ([]PlannedItinerary, error) {
+	var out []PlannedItinerary
+	return out, c.post("/routes/plan", req, &out)
+}
+
+// GTFSRTVehicles fetches the fleet and route state as a GTFS-Realtime
+// FeedMessage. The binary /gtfs-rt/vehicles.pb variant is only served when
+// the backend is built with -tags gtfsrt, so this client sticks to the
+// JSON-encoded feed.
+func (c *Client) GTFSRTVehicles() (GTFSRTFeedMessage, error) {
+	var out GTFSRTFeedMessage
+	return out, c.get("/gtfs-rt/vehicles.json", &out)
+}
+
+// HedgeFuel locks a fraction of fuel consumption at the current market
+// price for req.Months, returning the resulting hedge.
+func (c *Client) HedgeFuel(req FuelHedgeRequest) (FuelHedge, error) {
+	var out FuelHedge
+	return out, c.post("/fuel/hedge", req, &out)
+}
+
+// RouteLostProfit fetches the trailing lost-profit window for a route that
+// has skipped scheduled departures to grounded aircraft, curfew, or slot
+// contention.
+func (c *Client) RouteLostProfit(routeID string) (RouteLostProfit, error) {
+	var out RouteLostProfit
+	return out, c.get("/routes/"+routeID+"/lost", &out)
+}
+
+// OptimizeFleet finds the most profitable closed itinerary for each active
+// aircraft (or req.FleetIDs) over a ticks horizon, ranked by profit per
+// tick.
+func (c *Client) OptimizeFleet(req FleetOptimizeRequest) ([]FleetSuggestion, error) {
+	var out []FleetSuggestion
+	return out, c.post("/fleet/optimize", req, &out)
+}
+
+// OptimizeNetwork picks the profit-maximizing set of new aircraft-type/
+// market/frequency rotations to add, given the fleet-hours and airport
+// slots available.
+func (c *Client) OptimizeNetwork(req NetworkOptimizeRequest) (NetworkPlanResponse, error) {
+	var out NetworkPlanResponse
+	return out, c.post("/network/optimize", req, &out)
+}
+
+// SuggestFares returns Economy/Balanced/Premium fare quotes for a
+// prospective market, so callers can present a price/load/payback tradeoff
+// before picking a route's UserPrice.
+func (c *Client) SuggestFares(req SuggestFaresRequest) (FareSuggestion, error) {
+	var out FareSuggestion
+	return out, c.post("/routes/suggest-fares", req, &out)
+}
+
+// CongestionReport fetches the hour-by-hour departure histogram per
+// airport, for spotting slot/curfew pressure before it starts rejecting
+// new routes.
+func (c *Client) CongestionReport() ([]AirportCongestion, error) {
+	var out []AirportCongestion
+	return out, c.get("/analysis/congestion", &out)
+}
+
+// CommodityCatalog fetches the fixed list of freight types routes can be
+// configured to haul.
+func (c *Client) CommodityCatalog() ([]Commodity, error) {
+	var out []Commodity
+	return out, c.get("/commodities", &out)
+}
+
+// CommodityPrices fetches live commodity spot prices and supply at every
+// airport.
+func (c *Client) CommodityPrices() ([]AirportCommodityPrices, error) {
+	var out []AirportCommodityPrices
+	return out, c.get("/commodities/prices", &out)
+}
+
+// RouteCargoProfit fetches a route's cargo-side economics, separate from
+// its passenger ProfitPerTick.
+func (c *Client) RouteCargoProfit(routeID string) (RouteCargoProfit, error) {
+	var out RouteCargoProfit
+	return out, c.get("/routes/"+routeID+"/cargo", &out)
+}
+
+// ListSubsidies fetches every subsidy offer, award, and expiry.
+func (c *Client) ListSubsidies() ([]Subsidy, error) {
+	var out []Subsidy
+	return out, c.get("/subsidies", &out)
+}
+
+// ListDisruptions fetches every disruption alert published so far, live or
+// expired.
+func (c *Client) ListDisruptions() ([]DisruptionAlert, error) {
+	var out []DisruptionAlert
+	return out, c.get("/disruptions", &out)
+}
+
+// PublishDisruption injects a disruption alert affecting one or more
+// airports for a tick window.
+func (c *Client) PublishDisruption(a DisruptionAlert) (DisruptionAlert, error) {
+	var out DisruptionAlert
+	return out, c.post("/disruptions", a, &out)
+}
+
+// AnalyticsSummary aggregates logged flight legs between start and end
+// ticks into buckets keyed by groupBy (e.g. "hour", "aircraft", "route").
+func (c *Client) AnalyticsSummary(start, end int, groupBy string) ([]AnalyticsBucket, error) {
+	path := fmt.Sprintf("/analytics/summary?start=%d&end=%d&group_by=%s", start, end, groupBy)
+	var out []AnalyticsBucket
+	return out, c.get(path, &out)
+}
+
+func (c *Client) get(path string, out interface{}) error {
+	resp, err := c.httpClient().Get(c.BaseURL + path)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return decodeResponse(resp, out)
+}
+
+func (c *Client) post(path string, body interface{}, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	resp, err := c.httpClient().Post(c.BaseURL+path, "application/json", reader)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return decodeResponse(resp, out)
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+func decodeResponse(resp *http.Response, out interface{}) error {
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(resp.Body)
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		_ = json.Unmarshal(body, &apiErr)
+		if apiErr.Error == "" {
+			apiErr.Error = string(bytes.TrimSpace(body))
+		}
+		if apiErr.Error == "" {
+			apiErr.Error = resp.Status
+		}
+		return fmt.Errorf("airline_builder: %s", apiErr.Error)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,217 @@
+// Package fleetplan extends routeplan's single-itinerary search to the
+// whole fleet: a top-down dynamic program over (aircraft, current airport,
+// remaining ticks, cash-bucket) finds, for each owned aircraft, the
+// most profitable closed itinerary — one that starts and ends at the
+// aircraft's home airport — within a ticks horizon.
+//
+// Unlike routeplan's fuel-remaining bucket, an aircraft's range is not
+// tracked as a resource that depletes across legs: real aircraft refuel on
+// every turnaround, so "remaining range for this leg" is always the
+// aircraft's full RangeKm and only needs to be checked per hop, inside the
+// caller's LegFunc. That leaves current airport, remaining ticks, and cash
+// as the only state that actually carries across decisions.
+package fleetplan
+
+import "sort"
+
+// Airport is the subset of airport data the planner needs; callers adapt
+// their own airport type into this one.
+type Airport struct {
+	Ident    string
+	Lat, Lon float64
+	RunwayM  int
+}
+
+// Aircraft is one fleet member under consideration. Home is the airport the
+// itinerary must start and end at, since a closed loop is what keeps the
+// rest of the aircraft's schedule (crew, maintenance, gate) intact.
+type Aircraft struct {
+	ID      string
+	Home    string
+	RangeKm float64
+}
+
+// LegResult is the caller's cost/demand model evaluated for flying one
+// aircraft directly between two airports. Feasible false prunes the hop
+// entirely (out of range, runway too short, curfew, no slots, ...).
+type LegResult struct {
+	Feasible bool
+	Profit   float64
+	Ticks    int // block time plus turnaround, in ticks
+}
+
+// LegFunc evaluates flying one aircraft directly from one airport to
+// another.
+type LegFunc func(ac Aircraft, from, to Airport) LegResult
+
+// cashBuckets discretizes the cash-budget dimension into coarse bands
+// spanning [-cashBudget, +cashBudget] so the DP table stays small
+// regardless of how long the horizon runs.
+const cashBuckets = 10
+
+// Suggestion is one reconstructed closed itinerary for a single aircraft.
+type Suggestion struct {
+	AircraftID    string
+	Airports      []string // closed loop, starts and ends at the aircraft's Home
+	TotalProfit   float64
+	TicksUsed     int
+	ProfitPerTick float64
+	Utilization   float64 // TicksUsed / horizonTicks
+}
+
+// state is a DP table key: which airport the aircraft is sitting at, how
+// many ticks remain in the horizon, which coarse cash band the running plan
+// has reached, and which of the caller's mustVisit airports have been
+// covered so far.
+type state struct {
+	airport int
+	ticks   int
+	cashB   int
+	mask    uint32
+}
+
+type cell struct {
+	valid     bool
+	profit    float64
+	flewTo    int // index into candidates, or -1 if this state stops (rests out the horizon)
+	legTicks  int
+	nextCashB int // cash band the flewTo transition lands in, so reconstruction doesn't replay the leg
+}
+
+// OptimizeFleet runs the DP independently for each aircraft in fleet and
+// returns a ranked list (best ProfitPerTick first) of suggested closed
+// itineraries — one per aircraft for which a profitable loop back to Home
+// exists within horizonTicks. candidates bounds the state space to the
+// airports worth considering for this plan; pass a market's catchment, not
+// the whole airport database, or the table grows unmanageably.
+//
+// cashBudget caps how far into the red a single aircraft's plan may run the
+// cumulative cash position at any point along its itinerary; each aircraft
+// is evaluated as if it alone draws on the full budget, since the DP has no
+// way to share a running cash total across the independent per-aircraft
+// searches.
+//
+// mustVisit lists airports every returned itinerary must touch before it's
+// allowed to close back at Home, mirroring routeplan's must-visit bitmask —
+// entries beyond 32 are truncated (bitmask width).
+func OptimizeFleet(candidates []Airport, fleet []Aircraft, horizonTicks int, cashBudget float64, mustVisit []string, leg LegFunc) []Suggestion {
+	idx := make(map[string]int, len(candidates))
+	for i, a := range candidates {
+		idx[a.Ident] = i
+	}
+	if cashBudget <= 0 {
+		cashBudget = 1
+	}
+
+	mustBit := make(map[string]uint, len(mustVisit))
+	for i, id := range mustVisit {
+		if i >= 32 {
+			break
+		}
+		mustBit[id] = uint(i)
+	}
+	var fullMask uint32
+	if len(mustBit) > 0 {
+		fullMask = uint32(1)<<uint(len(mustBit)) - 1
+	}
+	maskBitFor := func(ident string) uint32 {
+		if b, ok := mustBit[ident]; ok {
+			return 1 << b
+		}
+		return 0
+	}
+	bandWidth := 2 * cashBudget / cashBuckets
+	bandOf := func(cumProfit float64) int {
+		b := int((cumProfit + cashBudget) / bandWidth)
+		if b < 0 {
+			b = 0
+		}
+		if b > cashBuckets-1 {
+			b = cashBuckets - 1
+		}
+		return b
+	}
+	bandFloor := func(b int) float64 {
+		return -cashBudget + float64(b)*bandWidth
+	}
+
+	var out []Suggestion
+	for _, ac := range fleet {
+		homeIdx, ok := idx[ac.Home]
+		if !ok || horizonTicks <= 0 {
+			continue
+		}
+
+		memo := make(map[state]cell)
+		var best func(st state) cell
+		best = func(st state) cell {
+			if c, ok := memo[st]; ok {
+				return c
+			}
+			// Stopping is only a valid end to the itinerary: back at Home,
+			// with every must-visit airport covered. Anywhere else, running
+			// out the clock mid-loop isn't a closed itinerary and the state
+			// is simply infeasible.
+			result := cell{valid: st.airport == homeIdx && st.mask&fullMask == fullMask, flewTo: -1}
+
+			from := candidates[st.airport]
+			for j, to := range candidates {
+				if j == st.airport || st.ticks <= 0 {
+					continue
+				}
+				res := leg(ac, from, to)
+				if !res.Feasible || res.Ticks <= 0 || res.Ticks > st.ticks {
+					continue
+				}
+				cumFloor := bandFloor(st.cashB) + res.Profit
+				if cumFloor < -cashBudget {
+					continue
+				}
+				nextCashB := bandOf(cumFloor)
+				nextMask := st.mask | maskBitFor(to.Ident)
+				next := best(state{airport: j, ticks: st.ticks - res.Ticks, cashB: nextCashB, mask: nextMask})
+				if !next.valid {
+					continue
+				}
+				candProfit := res.Profit + next.profit
+				if !result.valid || candProfit > result.profit {
+					result = cell{valid: true, profit: candProfit, flewTo: j, legTicks: res.Ticks, nextCashB: nextCashB}
+				}
+			}
+			memo[st] = result
+			return result
+		}
+
+		initMask := maskBitFor(ac.Home)
+		start := state{airport: homeIdx, ticks: horizonTicks, cashB: bandOf(0), mask: initMask}
+		root := best(start)
+		if !root.valid || root.profit <= 0 {
+			continue
+		}
+
+		airports := []string{candidates[homeIdx].Ident}
+		st, ticksUsed := start, 0
+		for {
+			c := memo[st]
+			if c.flewTo < 0 {
+				break
+			}
+			next := candidates[c.flewTo]
+			airports = append(airports, next.Ident)
+			ticksUsed += c.legTicks
+			st = state{airport: c.flewTo, ticks: st.ticks - c.legTicks, cashB: c.nextCashB, mask: st.mask | maskBitFor(next.Ident)}
+		}
+
+		out = append(out, Suggestion{
+			AircraftID:    ac.ID,
+			Airports:      airports,
+			TotalProfit:   root.profit,
+			TicksUsed:     ticksUsed,
+			ProfitPerTick: root.profit / float64(horizonTicks),
+			Utilization:   float64(ticksUsed) / float64(horizonTicks),
+		})
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].ProfitPerTick > out[j].ProfitPerTick })
+	return out
+}
@@ -0,0 +1,80 @@
+package fleetplan
+
+import "testing"
+
+var testAirports = []Airport{
+	{Ident: "AAA", Lat: 0, Lon: 0, RunwayM: 3000},
+	{Ident: "BBB", Lat: 0, Lon: 5, RunwayM: 3000},
+	{Ident: "CCC", Lat: 0, Lon: 20, RunwayM: 3000},
+}
+
+// flatLeg treats every feasible hop as worth a fixed profit and a fixed
+// tick cost, so tests can reason about distance/range without modeling a
+// real demand curve.
+func flatLeg(maxRangeKm float64) LegFunc {
+	return func(ac Aircraft, from, to Airport) LegResult {
+		dist := (to.Lon - from.Lon) * 111 // rough km/degree at the equator
+		if dist < 0 {
+			dist = -dist
+		}
+		if dist > ac.RangeKm {
+			return LegResult{}
+		}
+		return LegResult{Feasible: true, Profit: 1000, Ticks: 60}
+	}
+}
+
+func TestOptimizeFleetRespectsRange(t *testing.T) {
+	fleet := []Aircraft{{ID: "ac1", Home: "AAA", RangeKm: 600}}
+	out := OptimizeFleet(testAirports, fleet, 24*60, 1_000_000, nil, flatLeg(0))
+	if len(out) != 1 {
+		t.Fatalf("expected one suggestion, got %d", len(out))
+	}
+	for _, ident := range out[0].Airports {
+		if ident == "CCC" {
+			t.Fatalf("short-range aircraft should not reach CCC, got itinerary %v", out[0].Airports)
+		}
+	}
+}
+
+func TestOptimizeFleetOutOfRangeFindsNoLoop(t *testing.T) {
+	// AAA-BBB is ~555km; with a 200km range nothing is feasible, so the
+	// aircraft should be dropped from the results entirely rather than
+	// returned with an empty/invalid itinerary.
+	fleet := []Aircraft{{ID: "ac1", Home: "AAA", RangeKm: 200}}
+	out := OptimizeFleet(testAirports, fleet, 24*60, 1_000_000, nil, flatLeg(0))
+	if len(out) != 0 {
+		t.Fatalf("expected no suggestions for an aircraft that can't leave home, got %v", out)
+	}
+}
+
+func TestOptimizeFleetForcesMustVisit(t *testing.T) {
+	fleet := []Aircraft{{ID: "ac1", Home: "AAA", RangeKm: 6000}}
+	out := OptimizeFleet(testAirports, fleet, 24*60, 1_000_000, []string{"CCC"}, flatLeg(0))
+	if len(out) != 1 {
+		t.Fatalf("expected one suggestion, got %d", len(out))
+	}
+	visited := false
+	for _, ident := range out[0].Airports {
+		if ident == "CCC" {
+			visited = true
+		}
+	}
+	if !visited {
+		t.Fatalf("expected itinerary to visit must-visit airport CCC, got %v", out[0].Airports)
+	}
+	if out[0].Airports[0] != "AAA" || out[0].Airports[len(out[0].Airports)-1] != "AAA" {
+		t.Fatalf("expected a closed loop starting and ending at home, got %v", out[0].Airports)
+	}
+}
+
+func TestOptimizeFleetMustVisitUnreachableFindsNoLoop(t *testing.T) {
+	// CCC is out of range, so the must-visit requirement can never be
+	// satisfied and the aircraft should be dropped rather than returned
+	// with a loop that skips CCC.
+	fleet := []Aircraft{{ID: "ac1", Home: "AAA", RangeKm: 600}}
+	out := OptimizeFleet(testAirports, fleet, 24*60, 1_000_000, []string{"CCC"}, flatLeg(0))
+	if len(out) != 0 {
+		t.Fatalf("expected no suggestions when the must-visit airport is unreachable, got %v", out)
+	}
+}
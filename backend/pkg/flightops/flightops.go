@@ -0,0 +1,248 @@
+// Package flightops drives the per-aircraft flight state machine: phase
+// timers from gate departure to gate arrival, greedy rotation assignment of
+// owned aircraft to routes, great-circle position interpolation for live
+// tracking, and the append-only flight event log.
+package flightops
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"time"
+)
+
+// State is a node in the per-aircraft turnaround/flight state machine.
+type State string
+
+const (
+	Idle       State = "Idle"
+	Boarding   State = "Boarding"
+	Taxi       State = "Taxi"
+	Cruise     State = "Cruise"
+	Descent    State = "Descent"
+	Turnaround State = "Turnaround"
+)
+
+// Next returns the state that follows current once its timer expires.
+func Next(current State) State {
+	switch current {
+	case Idle:
+		return Boarding
+	case Boarding:
+		return Taxi
+	case Taxi:
+		return Cruise
+	case Cruise:
+		return Descent
+	case Descent:
+		return Turnaround
+	case Turnaround:
+		return Idle
+	default:
+		return Idle
+	}
+}
+
+// Fixed ground-phase durations; only Cruise scales with the leg flown.
+const (
+	BoardingMin = 20
+	TaxiMin     = 8
+	DescentMin  = 12
+)
+
+// Phases holds the per-minute duration of every phase of a single leg,
+// computed once when the leg is planned so the tick loop doesn't need the
+// aircraft's spec or the leg distance again while the timer counts down.
+type Phases struct {
+	BoardingMin   int `json:"boarding_min"`
+	TaxiMin       int `json:"taxi_min"`
+	CruiseMin     int `json:"cruise_min"`
+	DescentMin    int `json:"descent_min"`
+	TurnaroundMin int `json:"turnaround_min"`
+}
+
+// ComputePhases derives the timer for each phase of a leg of distKm flown at
+// cruiseKmh, finishing with a turnaroundMin ground stop at the far end.
+func ComputePhases(distKm, cruiseKmh float64, turnaroundMin int) Phases {
+	if cruiseKmh <= 0 {
+		cruiseKmh = 1
+	}
+	cruise := int((distKm / cruiseKmh) * 60)
+	if cruise < 1 {
+		cruise = 1
+	}
+	return Phases{
+		BoardingMin:   BoardingMin,
+		TaxiMin:       TaxiMin,
+		CruiseMin:     cruise,
+		DescentMin:    DescentMin,
+		TurnaroundMin: turnaroundMin,
+	}
+}
+
+// Duration returns how many minutes the given state runs for, per the
+// durations computed for the leg currently in progress.
+func (p Phases) Duration(s State) int {
+	switch s {
+	case Boarding:
+		return p.BoardingMin
+	case Taxi:
+		return p.TaxiMin
+	case Cruise:
+		return p.CruiseMin
+	case Descent:
+		return p.DescentMin
+	case Turnaround:
+		return p.TurnaroundMin
+	default:
+		return 0
+	}
+}
+
+// BlockMinutes is the full gate-to-gate time for one leg, including the
+// turnaround at the far end.
+func (p Phases) BlockMinutes() float64 {
+	return float64(p.BoardingMin + p.TaxiMin + p.CruiseMin + p.DescentMin + p.TurnaroundMin)
+}
+
+// MaxRotationMinutes is the operating window an aircraft has to complete its
+// assigned legs before it needs another aircraft to pick up the slack.
+const MaxRotationMinutes = 24 * 60
+
+// CanCoverRotation reports whether a single aircraft can physically fly
+// frequencyPerDay round trips of blockMinutes each inside a 24h window.
+func CanCoverRotation(blockMinutes float64, frequencyPerDay int) bool {
+	if frequencyPerDay <= 0 {
+		frequencyPerDay = 1
+	}
+	return blockMinutes*float64(frequencyPerDay) <= MaxRotationMinutes
+}
+
+// Candidate is one same-template aircraft available to operate a route.
+type Candidate struct {
+	AircraftID  string
+	AvailableIn int // ticks until free; 0 means free now
+}
+
+// AssignRotation picks concrete aircraft out of candidates, earliest
+// available first, stopping once enough are assigned to cover
+// frequencyPerDay legs of blockMinutes within a rolling 24h window. It
+// returns an error if no combination of the pool can cover the rotation.
+func AssignRotation(candidates []Candidate, frequencyPerDay int, blockMinutes float64) ([]string, error) {
+	if frequencyPerDay <= 0 {
+		frequencyPerDay = 1
+	}
+	sorted := make([]Candidate, len(candidates))
+	copy(sorted, candidates)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].AvailableIn < sorted[j].AvailableIn })
+
+	needed := blockMinutes * float64(frequencyPerDay)
+	var assigned []string
+	covered := 0.0
+	for _, c := range sorted {
+		if covered >= needed {
+			break
+		}
+		assigned = append(assigned, c.AircraftID)
+		covered += MaxRotationMinutes
+	}
+	if covered < needed {
+		return nil, fmt.Errorf("flightops: no combination of available aircraft can cover %d legs/day within 24h", frequencyPerDay)
+	}
+	return assigned, nil
+}
+
+func toRad(deg float64) float64 { return deg * math.Pi / 180 }
+func toDeg(rad float64) float64 { return rad * 180 / math.Pi }
+
+// angularDistance is the great-circle angle in radians between two points.
+func angularDistance(phi1, lam1, phi2, lam2 float64) float64 {
+	dphi := phi2 - phi1
+	dlam := lam2 - lam1
+	a := math.Sin(dphi/2)*math.Sin(dphi/2) + math.Cos(phi1)*math.Cos(phi2)*math.Sin(dlam/2)*math.Sin(dlam/2)
+	return 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
+
+// InterpolatePosition returns the point a fraction (0..1) of the way along
+// the great-circle path from (lat1,lon1) to (lat2,lon2), via spherical
+// interpolation so a long-haul midpoint doesn't cut a straight line across
+// the globe.
+func InterpolatePosition(lat1, lon1, lat2, lon2, frac float64) (lat, lon float64) {
+	if frac <= 0 {
+		return lat1, lon1
+	}
+	if frac >= 1 {
+		return lat2, lon2
+	}
+	phi1, lam1 := toRad(lat1), toRad(lon1)
+	phi2, lam2 := toRad(lat2), toRad(lon2)
+
+	d := angularDistance(phi1, lam1, phi2, lam2)
+	if d == 0 {
+		return lat1, lon1
+	}
+	a := math.Sin((1-frac)*d) / math.Sin(d)
+	b := math.Sin(frac*d) / math.Sin(d)
+
+	x := a*math.Cos(phi1)*math.Cos(lam1) + b*math.Cos(phi2)*math.Cos(lam2)
+	y := a*math.Cos(phi1)*math.Sin(lam1) + b*math.Cos(phi2)*math.Sin(lam2)
+	z := a*math.Sin(phi1) + b*math.Sin(phi2)
+
+	phi := math.Atan2(z, math.Sqrt(x*x+y*y))
+	lam := math.Atan2(y, x)
+	return toDeg(phi), toDeg(lam)
+}
+
+// TrackPoint is one sample along an aircraft's interpolated flight path.
+type TrackPoint struct {
+	Lat      float64 `json:"lat"`
+	Lon      float64 `json:"lon"`
+	Fraction float64 `json:"fraction"`
+}
+
+// Track samples n evenly-spaced points along the great-circle from
+// (lat1,lon1) to (lat2,lon2), including both endpoints.
+func Track(lat1, lon1, lat2, lon2 float64, n int) []TrackPoint {
+	if n < 2 {
+		n = 2
+	}
+	pts := make([]TrackPoint, n)
+	for i := 0; i < n; i++ {
+		frac := float64(i) / float64(n-1)
+		lat, lon := InterpolatePosition(lat1, lon1, lat2, lon2, frac)
+		pts[i] = TrackPoint{Lat: lat, Lon: lon, Fraction: frac}
+	}
+	return pts
+}
+
+// Event is one structured entry in the append-only flight event log used to
+// replay a game's flight history.
+type Event struct {
+	Tick       int       `json:"tick"`
+	Time       time.Time `json:"time"`
+	Type       string    `json:"type"` // "departure", "arrival", "diversion"
+	AircraftID string    `json:"aircraft_id"`
+	RouteID    string    `json:"route_id"`
+	From       string    `json:"from"`
+	To         string    `json:"to"`
+	Detail     string    `json:"detail,omitempty"`
+}
+
+// AppendEvent writes ev as one JSON line to the append-only event log at
+// path, creating the file if it doesn't exist yet.
+func AppendEvent(path string, ev Event) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	_, err = f.Write(data)
+	return err
+}
@@ -0,0 +1,405 @@
+// Package gtfs ingests a GTFS static bundle for network seeding and polls a
+// GTFS-Realtime feed for live delay/cancellation signals.
+package gtfs
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Stop is a row from stops.txt.
+type Stop struct {
+	ID       string
+	Code     string
+	Name     string
+	Lat      float64
+	Lon      float64
+	ParentID string
+}
+
+// RouteInfo is a row from routes.txt.
+type RouteInfo struct {
+	ID        string
+	ShortName string
+	LongName  string
+}
+
+// Trip is a row from trips.txt.
+type Trip struct {
+	ID      string
+	RouteID string
+}
+
+// StopTime is a row from stop_times.txt.
+type StopTime struct {
+	TripID       string
+	StopID       string
+	StopSequence int
+	Departure    string
+}
+
+// StaticBundle holds a parsed GTFS static feed.
+type StaticBundle struct {
+	Stops     []Stop
+	Routes    []RouteInfo
+	Trips     []Trip
+	StopTimes []StopTime
+
+	stopsByID map[string]Stop
+	tripsByID map[string]Trip
+}
+
+// LoadStaticBundle reads stops.txt, routes.txt, trips.txt, and stop_times.txt
+// from dir and returns the parsed bundle.
+func LoadStaticBundle(dir string) (*StaticBundle, error) {
+	stops, err := loadStops(dir + "/stops.txt")
+	if err != nil {
+		return nil, fmt.Errorf("gtfs: stops.txt: %w", err)
+	}
+	routes, err := loadRoutes(dir + "/routes.txt")
+	if err != nil {
+		return nil, fmt.Errorf("gtfs: routes.txt: %w", err)
+	}
+	trips, err := loadTrips(dir + "/trips.txt")
+	if err != nil {
+		return nil, fmt.Errorf("gtfs: trips.txt: %w", err)
+	}
+	stopTimes, err := loadStopTimes(dir + "/stop_times.txt")
+	if err != nil {
+		return nil, fmt.Errorf("gtfs: stop_times.txt: %w", err)
+	}
+
+	b := &StaticBundle{
+		Stops:     stops,
+		Routes:    routes,
+		Trips:     trips,
+		StopTimes: stopTimes,
+		stopsByID: make(map[string]Stop, len(stops)),
+		tripsByID: make(map[string]Trip, len(trips)),
+	}
+	for _, s := range stops {
+		b.stopsByID[s.ID] = s
+	}
+	for _, t := range trips {
+		b.tripsByID[t.ID] = t
+	}
+	return b, nil
+}
+
+// AirportSeed is a candidate airport derived from a GTFS stop, matched either
+// by an explicit IATA-style stop_code or by nearest lat/lon.
+type AirportSeed struct {
+	Ident string
+	Name  string
+	Lat   float64
+	Lon   float64
+}
+
+// MatchAirports returns one AirportSeed per stop whose stop_code looks like
+// an IATA/ICAO ident, for callers to reconcile against an existing airport
+// database (e.g. by ident) before falling back to nearest lat/lon matching.
+func (b *StaticBundle) MatchAirports() []AirportSeed {
+	seeds := make([]AirportSeed, 0, len(b.Stops))
+	for _, s := range b.Stops {
+		ident := strings.ToUpper(strings.TrimSpace(s.Code))
+		if len(ident) != 3 && len(ident) != 4 {
+			continue
+		}
+		seeds = append(seeds, AirportSeed{Ident: ident, Name: s.Name, Lat: s.Lat, Lon: s.Lon})
+	}
+	return seeds
+}
+
+// NearestStop returns the stop closest to (lat, lon) by great-circle distance,
+// used to match an airport that has no stop_code set.
+func (b *StaticBundle) NearestStop(lat, lon float64) (Stop, bool) {
+	var best Stop
+	bestDist := math.Inf(1)
+	found := false
+	for _, s := range b.Stops {
+		d := haversine(lat, lon, s.Lat, s.Lon)
+		if d < bestDist {
+			bestDist = d
+			best = s
+			found = true
+		}
+	}
+	return best, found
+}
+
+// RouteSeed is a candidate Route derived from scheduled trips between two
+// stops, ready to feed into the simulator's route builder.
+type RouteSeed struct {
+	FromIdent       string
+	ToIdent         string
+	FrequencyPerDay int
+}
+
+// SeedRoutes derives one RouteSeed per distinct (first stop, last stop) pair
+// seen across trips, with FrequencyPerDay set to the number of trips that
+// serve that pair. Stops lacking a resolvable 3/4-letter ident are skipped.
+func (b *StaticBundle) SeedRoutes() []RouteSeed {
+	tripStops := make(map[string][]StopTime)
+	for _, st := range b.StopTimes {
+		tripStops[st.TripID] = append(tripStops[st.TripID], st)
+	}
+
+	counts := make(map[[2]string]int)
+	for tripID, stops := range tripStops {
+		if len(stops) < 2 {
+			continue
+		}
+		sorted := append([]StopTime(nil), stops...)
+		sortStopTimes(sorted)
+		first := b.stopsByID[sorted[0].StopID]
+		last := b.stopsByID[sorted[len(sorted)-1].StopID]
+		fromIdent := strings.ToUpper(strings.TrimSpace(first.Code))
+		toIdent := strings.ToUpper(strings.TrimSpace(last.Code))
+		validIdent := func(s string) bool { return len(s) == 3 || len(s) == 4 }
+		if !validIdent(fromIdent) || !validIdent(toIdent) || fromIdent == toIdent {
+			continue
+		}
+		_ = tripID
+		counts[[2]string{fromIdent, toIdent}]++
+	}
+
+	seeds := make([]RouteSeed, 0, len(counts))
+	for pair, n := range counts {
+		seeds = append(seeds, RouteSeed{FromIdent: pair[0], ToIdent: pair[1], FrequencyPerDay: n})
+	}
+	return seeds
+}
+
+func sortStopTimes(st []StopTime) {
+	for i := 1; i < len(st); i++ {
+		for j := i; j > 0 && st[j].StopSequence < st[j-1].StopSequence; j-- {
+			st[j], st[j-1] = st[j-1], st[j]
+		}
+	}
+}
+
+func haversine(lat1, lon1, lat2, lon2 float64) float64 {
+	const R = 6371.0
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := toRad(lat2 - lat1)
+	dLon := toRad(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return R * 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
+
+func loadStops(path string) ([]Stop, error) {
+	rows, header, err := readCSV(path)
+	if err != nil {
+		return nil, err
+	}
+	idx := colIndex(header)
+	out := make([]Stop, 0, len(rows))
+	for _, rec := range rows {
+		lat, _ := strconv.ParseFloat(get(rec, idx, "stop_lat"), 64)
+		lon, _ := strconv.ParseFloat(get(rec, idx, "stop_lon"), 64)
+		out = append(out, Stop{
+			ID:       get(rec, idx, "stop_id"),
+			Code:     get(rec, idx, "stop_code"),
+			Name:     get(rec, idx, "stop_name"),
+			Lat:      lat,
+			Lon:      lon,
+			ParentID: get(rec, idx, "parent_station"),
+		})
+	}
+	return out, nil
+}
+
+func loadRoutes(path string) ([]RouteInfo, error) {
+	rows, header, err := readCSV(path)
+	if err != nil {
+		return nil, err
+	}
+	idx := colIndex(header)
+	out := make([]RouteInfo, 0, len(rows))
+	for _, rec := range rows {
+		out = append(out, RouteInfo{
+			ID:        get(rec, idx, "route_id"),
+			ShortName: get(rec, idx, "route_short_name"),
+			LongName:  get(rec, idx, "route_long_name"),
+		})
+	}
+	return out, nil
+}
+
+func loadTrips(path string) ([]Trip, error) {
+	rows, header, err := readCSV(path)
+	if err != nil {
+		return nil, err
+	}
+	idx := colIndex(header)
+	out := make([]Trip, 0, len(rows))
+	for _, rec := range rows {
+		out = append(out, Trip{
+			ID:      get(rec, idx, "trip_id"),
+			RouteID: get(rec, idx, "route_id"),
+		})
+	}
+	return out, nil
+}
+
+func loadStopTimes(path string) ([]StopTime, error) {
+	rows, header, err := readCSV(path)
+	if err != nil {
+		return nil, err
+	}
+	idx := colIndex(header)
+	out := make([]StopTime, 0, len(rows))
+	for _, rec := range rows {
+		seq, _ := strconv.Atoi(get(rec, idx, "stop_sequence"))
+		out = append(out, StopTime{
+			TripID:       get(rec, idx, "trip_id"),
+			StopID:       get(rec, idx, "stop_id"),
+			StopSequence: seq,
+			Departure:    get(rec, idx, "departure_time"),
+		})
+	}
+	return out, nil
+}
+
+func readCSV(path string) (rows [][]string, header []string, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = -1
+	header, err = reader.Read()
+	if err != nil {
+		return nil, nil, err
+	}
+	for {
+		rec, err := reader.Read()
+		if err != nil {
+			break
+		}
+		rows = append(rows, rec)
+	}
+	return rows, header, nil
+}
+
+func colIndex(header []string) map[string]int {
+	idx := make(map[string]int, len(header))
+	for i, h := range header {
+		idx[h] = i
+	}
+	return idx
+}
+
+func get(rec []string, idx map[string]int, col string) string {
+	i, ok := idx[col]
+	if !ok || i >= len(rec) {
+		return ""
+	}
+	return rec[i]
+}
+
+// FeedConfig describes one GTFS-Realtime feed to poll, as loaded from
+// data/feeds.json.
+type FeedConfig struct {
+	Name           string `json:"name"`
+	URL            string `json:"url"`
+	RefreshSeconds int    `json:"refresh_seconds"`
+	AuthHeader     string `json:"auth_header,omitempty"`
+	AuthToken      string `json:"auth_token,omitempty"`
+}
+
+// LoadFeedConfigs reads the feed list from path.
+func LoadFeedConfigs(path string) ([]FeedConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var feeds []FeedConfig
+	if err := json.Unmarshal(data, &feeds); err != nil {
+		return nil, err
+	}
+	return feeds, nil
+}
+
+// TripUpdate is the subset of a GTFS-Realtime TripUpdate entity this
+// simulator cares about: how late a trip is running, or whether it was
+// cancelled outright. Feeds are consumed via their JSON mirror here; a
+// binary transit_realtime.FeedMessage decoder can be dropped in behind the
+// same Poll signature once the protobuf dependency is vendored.
+type TripUpdate struct {
+	TripID       string `json:"trip_id"`
+	DelayMinutes int    `json:"delay_minutes"`
+	Cancelled    bool   `json:"cancelled"`
+}
+
+type feedMessage struct {
+	Entities []struct {
+		TripUpdate TripUpdate `json:"trip_update"`
+	} `json:"entity"`
+}
+
+// Poll fetches and decodes one GTFS-Realtime feed snapshot.
+func Poll(cfg FeedConfig) ([]TripUpdate, error) {
+	req, err := http.NewRequest(http.MethodGet, cfg.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.AuthHeader != "" && cfg.AuthToken != "" {
+		req.Header.Set(cfg.AuthHeader, cfg.AuthToken)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("gtfs: feed %s returned %s", cfg.Name, resp.Status)
+	}
+	var msg feedMessage
+	if err := json.NewDecoder(resp.Body).Decode(&msg); err != nil {
+		return nil, err
+	}
+	out := make([]TripUpdate, 0, len(msg.Entities))
+	for _, e := range msg.Entities {
+		out = append(out, e.TripUpdate)
+	}
+	return out, nil
+}
+
+// RunPoller polls every feed in cfgs on its own interval until stop is
+// closed, invoking apply with each decoded TripUpdate. Intended to be
+// started as a background goroutine next to startSim.
+func RunPoller(stop <-chan struct{}, cfgs []FeedConfig, apply func(TripUpdate)) {
+	for _, cfg := range cfgs {
+		go func(cfg FeedConfig) {
+			interval := time.Duration(cfg.RefreshSeconds) * time.Second
+			if interval <= 0 {
+				interval = 60 * time.Second
+			}
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-stop:
+					return
+				case <-ticker.C:
+					updates, err := Poll(cfg)
+					if err != nil {
+						continue
+					}
+					for _, u := range updates {
+						apply(u)
+					}
+				}
+			}
+		}(cfg)
+	}
+}
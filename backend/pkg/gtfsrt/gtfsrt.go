@@ -0,0 +1,78 @@
+// Package gtfsrt models the sim's live fleet as a GTFS-Realtime feed:
+// VehiclePosition, TripUpdate, and Alert entities built from the caller's
+// own fleet/route state. The types here mirror the transit_realtime.proto
+// field names so JSON callers get the familiar GTFS-RT shape; binary
+// protobuf marshaling (MarshalPB) lives behind the gtfsrt build tag in
+// gtfsrt_pb.go so builds that don't want google.golang.org/protobuf
+// vendored can still compile (gtfsrt_pb_stub.go covers that case).
+package gtfsrt
+
+import "time"
+
+// FeedMessage is the top-level GTFS-Realtime payload.
+type FeedMessage struct {
+	Header   FeedHeader   `json:"header"`
+	Entities []FeedEntity `json:"entity"`
+}
+
+// FeedHeader is transit_realtime.FeedHeader.
+type FeedHeader struct {
+	GTFSRealtimeVersion string `json:"gtfs_realtime_version"`
+	Timestamp           int64  `json:"timestamp"`
+}
+
+// FeedEntity wraps exactly one of VehiclePosition, TripUpdate, or Alert,
+// matching transit_realtime.FeedEntity's oneof-by-convention shape.
+type FeedEntity struct {
+	ID              string           `json:"id"`
+	VehiclePosition *VehiclePosition `json:"vehicle,omitempty"`
+	TripUpdate      *TripUpdate      `json:"trip_update,omitempty"`
+	Alert           *Alert           `json:"alert,omitempty"`
+}
+
+// Position is transit_realtime.Position.
+type Position struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// VehiclePosition is transit_realtime.VehiclePosition.
+type VehiclePosition struct {
+	VehicleID     string   `json:"vehicle_id"`
+	TripID        string   `json:"trip_id"`
+	CurrentStopID string   `json:"current_stop_id"`
+	Position      Position `json:"position"`
+	Timestamp     int64    `json:"timestamp"`
+}
+
+// StopTimeUpdate is transit_realtime.TripUpdate.StopTimeUpdate.
+type StopTimeUpdate struct {
+	StopID      string `json:"stop_id"`
+	ArrivalTime int64  `json:"arrival_time"`
+}
+
+// TripUpdate is transit_realtime.TripUpdate.
+type TripUpdate struct {
+	TripID          string           `json:"trip_id"`
+	VehicleID       string           `json:"vehicle_id"`
+	StopTimeUpdates []StopTimeUpdate `json:"stop_time_update"`
+}
+
+// Alert is the subset of transit_realtime.Alert this feed populates.
+type Alert struct {
+	InformedTripID string `json:"informed_trip_id"`
+	Cause          string `json:"cause"`
+	Effect         string `json:"effect"`
+	HeaderText     string `json:"header_text"`
+}
+
+// NewFeedMessage stamps a GTFS-RT header onto entities built by the caller.
+func NewFeedMessage(entities []FeedEntity) FeedMessage {
+	return FeedMessage{
+		Header: FeedHeader{
+			GTFSRealtimeVersion: "2.0",
+			Timestamp:           time.Now().Unix(),
+		},
+		Entities: entities,
+	}
+}
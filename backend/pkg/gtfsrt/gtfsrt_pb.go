@@ -0,0 +1,176 @@
+//go:build gtfsrt
+
+package gtfsrt
+
+import (
+	"math"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Field numbers below follow transit_realtime.proto (the GTFS-Realtime
+// spec) exactly, so bytes from MarshalPB decode with any standard
+// GTFS-RT consumer. Only the subset of each message this feed populates
+// is encoded; everything else is left at its proto default.
+
+func appendString(b []byte, num protowire.Number, s string) []byte {
+	if s == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, s)
+}
+
+func appendVarint(b []byte, num protowire.Number, v uint64) []byte {
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+func appendMessage(b []byte, num protowire.Number, msg []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, msg)
+}
+
+func appendFixed32(b []byte, num protowire.Number, v float32) []byte {
+	b = protowire.AppendTag(b, num, protowire.Fixed32Type)
+	return protowire.AppendFixed32(b, math.Float32bits(v))
+}
+
+func marshalPosition(p Position) []byte {
+	var b []byte
+	b = appendFixed32(b, 1, float32(p.Latitude))
+	b = appendFixed32(b, 2, float32(p.Longitude))
+	return b
+}
+
+func marshalTripDescriptor(tripID string) []byte {
+	return appendString(nil, 1, tripID)
+}
+
+func marshalVehicleDescriptor(vehicleID string) []byte {
+	return appendString(nil, 1, vehicleID)
+}
+
+func marshalStopTimeEvent(unixTime int64) []byte {
+	return appendVarint(nil, 2, uint64(unixTime))
+}
+
+func marshalStopTimeUpdate(u StopTimeUpdate) []byte {
+	var b []byte
+	b = appendString(b, 4, u.StopID)
+	if u.ArrivalTime != 0 {
+		b = appendMessage(b, 2, marshalStopTimeEvent(u.ArrivalTime))
+	}
+	return b
+}
+
+func marshalTripUpdate(tu TripUpdate) []byte {
+	var b []byte
+	b = appendMessage(b, 1, marshalTripDescriptor(tu.TripID))
+	if tu.VehicleID != "" {
+		b = appendMessage(b, 3, marshalVehicleDescriptor(tu.VehicleID))
+	}
+	for _, u := range tu.StopTimeUpdates {
+		b = appendMessage(b, 2, marshalStopTimeUpdate(u))
+	}
+	return b
+}
+
+func marshalVehiclePosition(vp VehiclePosition) []byte {
+	var b []byte
+	b = appendMessage(b, 1, marshalTripDescriptor(vp.TripID))
+	b = appendMessage(b, 2, marshalPosition(vp.Position))
+	b = appendString(b, 7, vp.CurrentStopID)
+	if vp.VehicleID != "" {
+		b = appendMessage(b, 8, marshalVehicleDescriptor(vp.VehicleID))
+	}
+	if vp.Timestamp != 0 {
+		b = appendVarint(b, 5, uint64(vp.Timestamp))
+	}
+	return b
+}
+
+func marshalTranslatedString(s string) []byte {
+	translation := appendString(nil, 1, s)
+	return appendMessage(nil, 1, translation)
+}
+
+// causeCode and effectCode map our plain-string Cause/Effect onto the
+// transit_realtime.Alert.Cause/Effect enums, defaulting to "unknown" for
+// anything this feed doesn't produce.
+func causeCode(s string) uint64 {
+	switch s {
+	case "MAINTENANCE":
+		return 9
+	case "TECHNICAL_PROBLEM":
+		return 3
+	default:
+		return 1 // UNKNOWN_CAUSE
+	}
+}
+
+func effectCode(s string) uint64 {
+	switch s {
+	case "SIGNIFICANT_DELAYS":
+		return 3
+	case "MODIFIED_SERVICE":
+		return 6
+	case "NO_SERVICE":
+		return 1
+	default:
+		return 8 // UNKNOWN_EFFECT
+	}
+}
+
+func marshalEntitySelector(tripID string) []byte {
+	if tripID == "" {
+		return nil
+	}
+	return appendMessage(nil, 4, marshalTripDescriptor(tripID))
+}
+
+func marshalAlert(a Alert) []byte {
+	var b []byte
+	if a.InformedTripID != "" {
+		b = appendMessage(b, 5, marshalEntitySelector(a.InformedTripID))
+	}
+	b = appendVarint(b, 6, causeCode(a.Cause))
+	b = appendVarint(b, 7, effectCode(a.Effect))
+	if a.HeaderText != "" {
+		b = appendMessage(b, 10, marshalTranslatedString(a.HeaderText))
+	}
+	return b
+}
+
+func marshalFeedEntity(e FeedEntity) []byte {
+	var b []byte
+	b = appendString(b, 1, e.ID)
+	if e.TripUpdate != nil {
+		b = appendMessage(b, 3, marshalTripUpdate(*e.TripUpdate))
+	}
+	if e.VehiclePosition != nil {
+		b = appendMessage(b, 4, marshalVehiclePosition(*e.VehiclePosition))
+	}
+	if e.Alert != nil {
+		b = appendMessage(b, 5, marshalAlert(*e.Alert))
+	}
+	return b
+}
+
+func marshalFeedHeader(h FeedHeader) []byte {
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	b = protowire.AppendString(b, h.GTFSRealtimeVersion)
+	b = appendVarint(b, 3, uint64(h.Timestamp))
+	return b
+}
+
+// MarshalPB encodes m as a binary transit_realtime.FeedMessage.
+func (m FeedMessage) MarshalPB() ([]byte, error) {
+	var b []byte
+	b = appendMessage(b, 1, marshalFeedHeader(m.Header))
+	for _, e := range m.Entities {
+		b = appendMessage(b, 2, marshalFeedEntity(e))
+	}
+	return b, nil
+}
@@ -0,0 +1,13 @@
+//go:build !gtfsrt
+
+package gtfsrt
+
+import "fmt"
+
+// MarshalPB is unavailable without the gtfsrt build tag, which is what
+// pulls in google.golang.org/protobuf. Build with `-tags gtfsrt` to get a
+// real binary transit_realtime.FeedMessage out of /gtfs-rt/vehicles.pb;
+// otherwise use the JSON feed at /gtfs-rt/vehicles.json.
+func (m FeedMessage) MarshalPB() ([]byte, error) {
+	return nil, fmt.Errorf("gtfsrt: binary protobuf marshaling requires building with -tags gtfsrt")
+}
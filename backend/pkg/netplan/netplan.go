@@ -0,0 +1,225 @@
+// Package netplan decides which brand-new route rotations are worth
+// standing up across the whole network at once, given a ranked list of
+// candidate (aircraft template, market, frequency) additions that the
+// caller has already priced with its own demand/cost model.
+//
+// It's fleetplan's DP turned inside out: fleetplan finds the best closed
+// itinerary for one already-owned aircraft; OptimizeNetwork decides which
+// of many independent candidate rotations to add at all, sharing a
+// handful of scarce resources — block-hours per aircraft type, departure
+// slots per airport, and cash — across every candidate at once. This is
+// the classic Planeteer-style DP: enumerate states of the scarce shared
+// resources, take the max over "add the next candidate or skip it," and
+// memoize on discretized buckets so the table stays small regardless of
+// how many candidates are offered or how large the budgets are.
+package netplan
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Candidate is one prospective route addition: a daily rotation of one
+// aircraft template on a market at a given frequency, pre-costed by the
+// caller's own demand and cost model.
+type Candidate struct {
+	AircraftType string
+	From, To     string
+	Frequency    int
+
+	// Profit is this candidate's expected daily net profit if added, on its
+	// own — the "monopoly profit" used for the admissible upper-bound
+	// prune, before accounting for resources it shares with the others.
+	Profit float64
+
+	// HoursPerDay is the block-plus-turnaround time one daily rotation
+	// consumes from the shared pool of AircraftType's remaining fleet-hours.
+	HoursPerDay float64
+
+	// Slots is the departure slots this rotation consumes per day at each
+	// of From and To.
+	Slots int
+
+	// Cash is the incremental cash required to stand the route up (aircraft
+	// down payment/lease plus working capital).
+	Cash float64
+}
+
+// NetworkPlan is the profit-maximizing set of route additions
+// OptimizeNetwork found, in candidate order, plus the resulting expected
+// daily net cash flow.
+type NetworkPlan struct {
+	Additions    []Candidate
+	DailyNetCash float64
+}
+
+// resourceBuckets discretizes each scarce resource's remaining capacity
+// into this many bands, so the memo table size is independent of the raw
+// hours/slots/cash magnitudes.
+const resourceBuckets = 20
+
+// cell is a DP table entry: the best profit achievable from this state
+// onward, whether the candidate this state is keyed on was taken, and (if
+// so) the exact resource levels that choice leaves behind, so
+// reconstruction doesn't need to replay the feasibility check.
+type cell struct {
+	profit    float64
+	take      bool
+	nextHours []float64
+	nextSlots []int
+	nextCash  float64
+}
+
+// OptimizeNetwork searches candidates for the profit-maximizing subset
+// that fits within budgetCash and the given per-aircraft-type remaining
+// fleet-hours and per-airport remaining departure slots for the planning
+// horizon. It models the choice as a 0/1 knapsack shared across three
+// scarce resources, memoized on discretized buckets, and prunes any branch
+// whose remaining candidates can't possibly beat zero added profit, using
+// each candidate's own monopoly Profit (which ignores resource sharing) as
+// the admissible bound.
+func OptimizeNetwork(candidates []Candidate, budgetCash float64, hoursPerType map[string]float64, slotsPerAirport map[string]int) NetworkPlan {
+	types := sortedKeys(hoursPerType)
+	airports := make([]string, 0, len(slotsPerAirport))
+	for a := range slotsPerAirport {
+		airports = append(airports, a)
+	}
+	sort.Strings(airports)
+
+	typeIdx := make(map[string]int, len(types))
+	for i, t := range types {
+		typeIdx[t] = i
+	}
+	airportIdx := make(map[string]int, len(airports))
+	for i, a := range airports {
+		airportIdx[a] = i
+	}
+
+	hoursTotal := make([]float64, len(types))
+	for i, t := range types {
+		hoursTotal[i] = hoursPerType[t]
+	}
+	slotsTotal := make([]int, len(airports))
+	for i, a := range airports {
+		slotsTotal[i] = slotsPerAirport[a]
+	}
+	if budgetCash <= 0 {
+		budgetCash = 1
+	}
+
+	// suffixBound[i] is the admissible upper bound on profit achievable
+	// from candidates[i:] alone: the sum of every remaining candidate's own
+	// monopoly profit, ignoring whatever resources they'd actually share.
+	suffixBound := make([]float64, len(candidates)+1)
+	for i := len(candidates) - 1; i >= 0; i-- {
+		add := candidates[i].Profit
+		if add < 0 {
+			add = 0
+		}
+		suffixBound[i] = suffixBound[i+1] + add
+	}
+
+	bucketOf := func(remaining, total float64) int {
+		if total <= 0 {
+			return 0
+		}
+		b := int(remaining / total * resourceBuckets)
+		return clampBucket(b)
+	}
+	cashBucketOf := func(remaining float64) int {
+		return clampBucket(int(remaining / budgetCash * resourceBuckets))
+	}
+	key := func(i int, hoursRem []float64, slotsRem []int, cashRem float64) string {
+		var b strings.Builder
+		fmt.Fprintf(&b, "%d|", i)
+		for ti, h := range hoursRem {
+			fmt.Fprintf(&b, "%d,", bucketOf(h, hoursTotal[ti]))
+		}
+		b.WriteByte('|')
+		for ai, s := range slotsRem {
+			fmt.Fprintf(&b, "%d,", bucketOf(float64(s), float64(slotsTotal[ai])))
+		}
+		fmt.Fprintf(&b, "|%d", cashBucketOf(cashRem))
+		return b.String()
+	}
+
+	memo := make(map[string]cell)
+	var best func(i int, hoursRem []float64, slotsRem []int, cashRem float64) cell
+	best = func(i int, hoursRem []float64, slotsRem []int, cashRem float64) cell {
+		if i >= len(candidates) || suffixBound[i] <= 0 {
+			return cell{}
+		}
+		k := key(i, hoursRem, slotsRem, cashRem)
+		if c, ok := memo[k]; ok {
+			return c
+		}
+
+		// Always allowed: skip this candidate and move on.
+		result := best(i+1, hoursRem, slotsRem, cashRem)
+
+		cnd := candidates[i]
+		hi, hok := typeIdx[cnd.AircraftType]
+		fi, fok := airportIdx[cnd.From]
+		ti, tok := airportIdx[cnd.To]
+		feasible := hok && fok && tok &&
+			cnd.Cash <= cashRem &&
+			cnd.HoursPerDay <= hoursRem[hi] &&
+			cnd.Slots <= slotsRem[fi] &&
+			cnd.Slots <= slotsRem[ti]
+		if feasible {
+			nextHours := append([]float64(nil), hoursRem...)
+			nextHours[hi] -= cnd.HoursPerDay
+			nextSlots := append([]int(nil), slotsRem...)
+			nextSlots[fi] -= cnd.Slots
+			nextSlots[ti] -= cnd.Slots
+			nextCash := cashRem - cnd.Cash
+
+			take := best(i+1, nextHours, nextSlots, nextCash)
+			if candProfit := cnd.Profit + take.profit; candProfit > result.profit {
+				result = cell{profit: candProfit, take: true, nextHours: nextHours, nextSlots: nextSlots, nextCash: nextCash}
+			}
+		}
+
+		memo[k] = result
+		return result
+	}
+
+	initHours := append([]float64(nil), hoursTotal...)
+	initSlots := append([]int(nil), slotsTotal...)
+	root := best(0, initHours, initSlots, budgetCash)
+
+	var additions []Candidate
+	hoursRem, slotsRem, cashRem := initHours, initSlots, budgetCash
+	for i := 0; i < len(candidates); i++ {
+		c, ok := memo[key(i, hoursRem, slotsRem, cashRem)]
+		if !ok {
+			break // pruned: nothing from here on was ever worth taking
+		}
+		if c.take {
+			additions = append(additions, candidates[i])
+			hoursRem, slotsRem, cashRem = c.nextHours, c.nextSlots, c.nextCash
+		}
+	}
+
+	return NetworkPlan{Additions: additions, DailyNetCash: root.profit}
+}
+
+func clampBucket(b int) int {
+	if b < 0 {
+		return 0
+	}
+	if b > resourceBuckets-1 {
+		return resourceBuckets - 1
+	}
+	return b
+}
+
+func sortedKeys(m map[string]float64) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,49 @@
+package netplan
+
+import "testing"
+
+func TestOptimizeNetworkPicksBothWhenResourcesAllow(t *testing.T) {
+	candidates := []Candidate{
+		{AircraftType: "A320", From: "AAA", To: "BBB", Frequency: 1, Profit: 1000, HoursPerDay: 4, Slots: 1, Cash: 10_000},
+		{AircraftType: "A320", From: "AAA", To: "CCC", Frequency: 1, Profit: 800, HoursPerDay: 4, Slots: 1, Cash: 10_000},
+	}
+	plan := OptimizeNetwork(candidates, 100_000,
+		map[string]float64{"A320": 16},
+		map[string]int{"AAA": 4, "BBB": 2, "CCC": 2})
+
+	if len(plan.Additions) != 2 {
+		t.Fatalf("expected both candidates added, got %d (%+v)", len(plan.Additions), plan.Additions)
+	}
+	if plan.DailyNetCash != 1800 {
+		t.Fatalf("expected daily net cash 1800, got %.2f", plan.DailyNetCash)
+	}
+}
+
+func TestOptimizeNetworkSkipsWhenFleetHoursRunOut(t *testing.T) {
+	candidates := []Candidate{
+		{AircraftType: "A320", From: "AAA", To: "BBB", Frequency: 1, Profit: 1000, HoursPerDay: 10, Slots: 1, Cash: 1},
+		{AircraftType: "A320", From: "AAA", To: "CCC", Frequency: 1, Profit: 800, HoursPerDay: 10, Slots: 1, Cash: 1},
+	}
+	// Only 16 hours/day of A320 time exists; both candidates want 10h, so
+	// only the more profitable one should fit.
+	plan := OptimizeNetwork(candidates, 1_000_000,
+		map[string]float64{"A320": 16},
+		map[string]int{"AAA": 4, "BBB": 2, "CCC": 2})
+
+	if len(plan.Additions) != 1 || plan.Additions[0].To != "BBB" {
+		t.Fatalf("expected only the AAA-BBB candidate to fit, got %+v", plan.Additions)
+	}
+}
+
+func TestOptimizeNetworkRejectsUnaffordableCandidate(t *testing.T) {
+	candidates := []Candidate{
+		{AircraftType: "A320", From: "AAA", To: "BBB", Frequency: 1, Profit: 1000, HoursPerDay: 4, Slots: 1, Cash: 50_000},
+	}
+	plan := OptimizeNetwork(candidates, 10_000,
+		map[string]float64{"A320": 16},
+		map[string]int{"AAA": 4, "BBB": 2})
+
+	if len(plan.Additions) != 0 {
+		t.Fatalf("expected no additions when cash can't cover the candidate, got %+v", plan.Additions)
+	}
+}
@@ -0,0 +1,177 @@
+// Package routeplan generalizes a single from/via/to hop into an
+// arbitrary-length chain: a bottom-up dynamic program over
+// (airport, fuel-remaining bucket, must-visit bitmask) finds the most
+// profitable multi-leg itineraries within a hop budget.
+package routeplan
+
+import (
+	"math"
+	"sort"
+)
+
+// Airport is the subset of airport data the planner needs; callers adapt
+// their own airport type into this one.
+type Airport struct {
+	Ident       string
+	Lat, Lon    float64
+	RunwayM     int
+	SlotsPerDay int
+	Curfew      bool
+	CurfewStart int
+	CurfewEnd   int
+}
+
+// LegResult is the caller's cost/demand model evaluated for one candidate
+// hop. Feasible false means the hop is pruned from the search entirely
+// (out of range, runway too short, curfew, no slots, ...).
+type LegResult struct {
+	Feasible     bool
+	Profit       float64
+	FuelFraction float64 // fraction of the aircraft's full range burned by this leg, 0..1
+	BlockMinutes float64
+}
+
+// LegFunc evaluates flying directly from one airport to another.
+type LegFunc func(from, to Airport) LegResult
+
+// fuelBuckets discretizes the aircraft's full range into ~20 steps so the DP
+// table stays small regardless of how granular real fuel burn is.
+const fuelBuckets = 20
+
+// Itinerary is one reconstructed multi-leg route.
+type Itinerary struct {
+	Airports          []string
+	TotalProfit       float64
+	TotalBlockMinutes float64
+}
+
+// state is a DP table key: which airport, how much fuel-bucket range is
+// left, and which of the must-visit airports have been covered so far.
+type state struct {
+	airport int
+	fuel    int
+	mask    uint32
+}
+
+type cell struct {
+	profit float64
+	block  float64
+	from   state
+	valid  bool
+}
+
+// PlanBestRoutes runs the DP for up to maxHops legs starting at start, and
+// returns the topN most profitable itineraries that visit every airport in
+// mustVisit and land on one of acceptableEnds (any candidate airport if
+// acceptableEnds is empty). candidates bounds the state space to the
+// airports worth considering for this plan; pass a market's catchment, not
+// the whole airport database, or the table grows unmanageably. mustVisit
+// beyond 32 entries is truncated (bitmask width).
+func PlanBestRoutes(candidates []Airport, start string, maxHops int, mustVisit []string, acceptableEnds []string, leg LegFunc, topN int) []Itinerary {
+	idx := make(map[string]int, len(candidates))
+	for i, a := range candidates {
+		idx[a.Ident] = i
+	}
+	startIdx, ok := idx[start]
+	if !ok || maxHops <= 0 {
+		return nil
+	}
+
+	mustBit := make(map[string]uint)
+	for i, id := range mustVisit {
+		if i >= 32 {
+			break
+		}
+		mustBit[id] = uint(i)
+	}
+	var fullMask uint32
+	if len(mustBit) > 0 {
+		fullMask = uint32(1)<<uint(len(mustBit)) - 1
+	}
+	initMask := uint32(0)
+	if b, ok := mustBit[start]; ok {
+		initMask |= 1 << b
+	}
+
+	endSet := make(map[string]bool, len(acceptableEnds))
+	for _, e := range acceptableEnds {
+		endSet[e] = true
+	}
+
+	// layers[hop] is the DP table after exactly hop legs.
+	layers := make([]map[state]cell, maxHops+1)
+	layers[0] = map[state]cell{{airport: startIdx, fuel: fuelBuckets, mask: initMask}: {valid: true}}
+
+	for hop := 0; hop < maxHops; hop++ {
+		next := make(map[state]cell)
+		for st, c := range layers[hop] {
+			from := candidates[st.airport]
+			for j, to := range candidates {
+				if j == st.airport {
+					continue
+				}
+				res := leg(from, to)
+				if !res.Feasible {
+					continue
+				}
+				used := int(math.Ceil(res.FuelFraction * float64(fuelBuckets)))
+				if used < 1 {
+					used = 1
+				}
+				if used > st.fuel {
+					continue
+				}
+				nmask := st.mask
+				if b, ok := mustBit[to.Ident]; ok {
+					nmask |= 1 << b
+				}
+				nst := state{airport: j, fuel: st.fuel - used, mask: nmask}
+				np := c.profit + res.Profit
+				if existing, ok := next[nst]; !ok || np > existing.profit {
+					next[nst] = cell{profit: np, block: c.block + res.BlockMinutes, from: st, valid: true}
+				}
+			}
+		}
+		layers[hop+1] = next
+	}
+
+	type finalist struct {
+		hop int
+		st  state
+		c   cell
+	}
+	var finalists []finalist
+	for hop := 1; hop <= maxHops; hop++ {
+		for st, c := range layers[hop] {
+			if fullMask != 0 && st.mask&fullMask != fullMask {
+				continue
+			}
+			if len(endSet) > 0 && !endSet[candidates[st.airport].Ident] {
+				continue
+			}
+			finalists = append(finalists, finalist{hop: hop, st: st, c: c})
+		}
+	}
+	sort.Slice(finalists, func(i, j int) bool { return finalists[i].c.profit > finalists[j].c.profit })
+	if topN > 0 && len(finalists) > topN {
+		finalists = finalists[:topN]
+	}
+
+	itins := make([]Itinerary, 0, len(finalists))
+	for _, f := range finalists {
+		path := []string{candidates[f.st.airport].Ident}
+		hop, st := f.hop, f.st
+		for hop > 0 {
+			c := layers[hop][st]
+			path = append([]string{candidates[c.from.airport].Ident}, path...)
+			st = c.from
+			hop--
+		}
+		itins = append(itins, Itinerary{
+			Airports:          path,
+			TotalProfit:       f.c.profit,
+			TotalBlockMinutes: f.c.block,
+		})
+	}
+	return itins
+}
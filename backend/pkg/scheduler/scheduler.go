@@ -0,0 +1,180 @@
+// Package scheduler fits rotations into curfew-clear minute-of-day
+// departure windows. It works in two phases: FeasibleDepartureIntervals
+// derives, per rotation, the minutes that clear both endpoints' curfews,
+// then Solve picks one interval per rotation highest-profit-first while
+// respecting each aircraft's sequential availability.
+package scheduler
+
+import "sort"
+
+// CurfewWindow is the local closed-to-traffic window at an airport,
+// expressed as hour-of-day bounds (e.g. 22->6 blocks 22:00 through 06:00).
+type CurfewWindow struct {
+	Active    bool
+	StartHour int
+	EndHour   int
+}
+
+// blocks reports whether minuteOfDay (wrapped to 0..1439) falls inside the
+// curfew.
+func (c CurfewWindow) blocks(minuteOfDay int) bool {
+	if !c.Active || c.StartHour == c.EndHour {
+		return false
+	}
+	m := ((minuteOfDay % 1440) + 1440) % 1440
+	startMin := c.StartHour * 60
+	endMin := c.EndHour * 60
+	if startMin < endMin {
+		return m >= startMin && m < endMin
+	}
+	return m >= startMin || m < endMin
+}
+
+// Interval is a maximal contiguous span of feasible minute-of-day
+// departures, EndMin exclusive.
+type Interval struct {
+	StartMin int
+	EndMin   int
+}
+
+// FeasibleDepartureIntervals returns the minute-of-day departure windows for
+// a leg of blockMinutes such that neither the departure (at dep) nor the
+// arrival (at dep+blockMinutes) falls inside either endpoint's curfew. A
+// window that wraps past midnight is merged into a single interval that may
+// start before 0 so callers don't need to special-case the wraparound.
+func FeasibleDepartureIntervals(origin, dest CurfewWindow, blockMinutes float64) []Interval {
+	block := int(blockMinutes)
+	var feasible []Interval
+	var cur *Interval
+	flush := func() {
+		if cur != nil {
+			feasible = append(feasible, *cur)
+			cur = nil
+		}
+	}
+	for m := 0; m < 1440; m++ {
+		ok := !origin.blocks(m) && !dest.blocks(m+block)
+		if ok {
+			if cur == nil {
+				cur = &Interval{StartMin: m, EndMin: m + 1}
+			} else {
+				cur.EndMin = m + 1
+			}
+		} else {
+			flush()
+		}
+	}
+	flush()
+	if len(feasible) > 1 && feasible[0].StartMin == 0 && feasible[len(feasible)-1].EndMin == 1440 {
+		feasible[0].StartMin = feasible[len(feasible)-1].StartMin - 1440
+		feasible = feasible[:len(feasible)-1]
+	}
+	return feasible
+}
+
+// Rotation is one candidate (aircraft, route) pairing to be scheduled.
+type Rotation struct {
+	AircraftID    string
+	RouteID       string
+	BlockMinutes  float64
+	TurnaroundMin int
+	DailyProfit   float64
+	Origin        CurfewWindow
+	Dest          CurfewWindow
+}
+
+// Assignment is the minute-of-day departure and arrival chosen for one
+// rotation's leg.
+type Assignment struct {
+	AircraftID   string `json:"aircraft_id"`
+	RouteID      string `json:"route_id"`
+	DepartureMin int    `json:"departure_min"`
+	ArrivalMin   int    `json:"arrival_min"`
+}
+
+// Unschedulable records why a rotation couldn't be fit into the day.
+type Unschedulable struct {
+	AircraftID string `json:"aircraft_id"`
+	RouteID    string `json:"route_id"`
+	Reason     string `json:"reason"`
+}
+
+// Result is the outcome of Solve.
+type Result struct {
+	Assignments   []Assignment    `json:"assignments"`
+	Unschedulable []Unschedulable `json:"unschedulable"`
+}
+
+type booking struct {
+	depMin, readyMin int // readyMin = arrival + turnaround
+}
+
+// Solve implements the search phase: process rotations highest-profit-first
+// and greedily take the earliest feasible departure minute that clears both
+// endpoints' curfews and doesn't violate the aircraft's sequential
+// availability (arrival + turnaround <= next departure). This stands in for
+// a full ILP solve (e.g. github.com/draffensperger/golp) until that
+// dependency is vendored; for the rotation counts a single fleet schedules
+// in a day, greedy-by-profit with per-minute feasibility checking finds the
+// same shape of answer without requiring an LP solver to be installed.
+func Solve(rotations []Rotation) Result {
+	order := make([]Rotation, len(rotations))
+	copy(order, rotations)
+	sort.Slice(order, func(i, j int) bool { return order[i].DailyProfit > order[j].DailyProfit })
+
+	busy := make(map[string][]booking)
+	var res Result
+	for _, r := range order {
+		intervals := FeasibleDepartureIntervals(r.Origin, r.Dest, r.BlockMinutes)
+		if len(intervals) == 0 {
+			res.Unschedulable = append(res.Unschedulable, Unschedulable{
+				AircraftID: r.AircraftID,
+				RouteID:    r.RouteID,
+				Reason:     "no departure minute clears both endpoints' curfew windows",
+			})
+			continue
+		}
+
+		dep, ok := earliestClear(intervals, busy[r.AircraftID], int(r.BlockMinutes), r.TurnaroundMin)
+		if !ok {
+			res.Unschedulable = append(res.Unschedulable, Unschedulable{
+				AircraftID: r.AircraftID,
+				RouteID:    r.RouteID,
+				Reason:     "curfew-clear departure windows conflict with this aircraft's other assigned legs",
+			})
+			continue
+		}
+
+		arr := dep + int(r.BlockMinutes)
+		busy[r.AircraftID] = append(busy[r.AircraftID], booking{depMin: dep, readyMin: arr + r.TurnaroundMin})
+		res.Assignments = append(res.Assignments, Assignment{
+			AircraftID:   r.AircraftID,
+			RouteID:      r.RouteID,
+			DepartureMin: ((dep % 1440) + 1440) % 1440,
+			ArrivalMin:   ((arr % 1440) + 1440) % 1440,
+		})
+	}
+	return res
+}
+
+// earliestClear finds the earliest minute within intervals whose span
+// (dep..dep+blockMinutes+turnaroundMin) doesn't overlap any of the
+// aircraft's existing bookings.
+func earliestClear(intervals []Interval, existing []booking, blockMinutes, turnaroundMin int) (int, bool) {
+	for _, iv := range intervals {
+		for dep := iv.StartMin; dep < iv.EndMin; dep++ {
+			ready := dep + blockMinutes + turnaroundMin
+			conflict := false
+			for _, b := range existing {
+				if dep < b.readyMin && ready > b.depMin {
+					conflict = true
+					break
+				}
+			}
+			if !conflict {
+				return dep, true
+			}
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"reflect"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// jsonPatchOp is one RFC 6902 operation.
+type jsonPatchOp struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// streamMessage is the envelope sent over /stream: either a state delta or
+// a discrete event.
+type streamMessage struct {
+	Type    string        `json:"type"`
+	Patch   []jsonPatchOp `json:"patch,omitempty"`
+	Event   string        `json:"event,omitempty"`
+	Payload interface{}   `json:"payload,omitempty"`
+}
+
+// hub fans tick/event messages out to every subscribed WebSocket client.
+type hub struct {
+	mu          sync.Mutex
+	subscribers map[chan streamMessage]bool
+}
+
+func newHub() *hub {
+	return &hub{subscribers: make(map[chan streamMessage]bool)}
+}
+
+func (h *hub) subscribe() chan streamMessage {
+	ch := make(chan streamMessage, 16)
+	h.mu.Lock()
+	h.subscribers[ch] = true
+	h.mu.Unlock()
+	return ch
+}
+
+func (h *hub) unsubscribe(ch chan streamMessage) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.subscribers[ch]; ok {
+		delete(h.subscribers, ch)
+		close(ch)
+	}
+}
+
+func (h *hub) broadcast(msg streamMessage) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subscribers {
+		select {
+		case ch <- msg:
+		default:
+			// slow subscriber; drop the message rather than block the tick loop.
+		}
+	}
+}
+
+var (
+	streamHub       = newHub()
+	lastBroadcast   map[string]interface{}
+	lastBroadcastMu sync.Mutex
+)
+
+var upgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+func handleStream(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("stream: upgrade failed: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	ch := streamHub.subscribe()
+	defer streamHub.unsubscribe(ch)
+
+	// Send a full snapshot on connect so the client doesn't need a separate
+	// GET /state round trip before it can start applying patches.
+	stateMu.Lock()
+	snapshot := state
+	stateMu.Unlock()
+	_ = conn.WriteJSON(streamMessage{Type: "snapshot", Payload: snapshot})
+
+	for msg := range ch {
+		if err := conn.WriteJSON(msg); err != nil {
+			return
+		}
+	}
+}
+
+// notifyLocked diffs the current state against the last broadcast snapshot
+// and pushes a JSON-Patch delta to every /stream subscriber. Must be called
+// with stateMu held, at the end of advanceTickLocked.
+func notifyLocked() {
+	raw, err := json.Marshal(state)
+	if err != nil {
+		return
+	}
+	var current map[string]interface{}
+	if err := json.Unmarshal(raw, &current); err != nil {
+		return
+	}
+
+	lastBroadcastMu.Lock()
+	patch := diffToPatch("", lastBroadcast, current)
+	lastBroadcast = current
+	lastBroadcastMu.Unlock()
+
+	if len(patch) == 0 {
+		return
+	}
+	streamHub.broadcast(streamMessage{Type: "patch", Patch: patch})
+}
+
+// addEvent broadcasts a discrete event (route_created, aircraft_delivered,
+// maintenance_started, disruption_grounded, ...) independent of the tick delta.
+func addEvent(event string, payload interface{}) {
+	streamHub.broadcast(streamMessage{Type: "event", Event: event, Payload: payload})
+}
+
+// diffToPatch produces a minimal set of RFC 6902 "replace"/"add"/"remove"
+// operations turning `before` into `after`. It only recurses into nested
+// JSON objects; array-valued fields (routes, fleet) are replaced wholesale
+// since positional diffing isn't worth the complexity for a few dozen items.
+func diffToPatch(path string, before, after map[string]interface{}) []jsonPatchOp {
+	var ops []jsonPatchOp
+	for k, av := range after {
+		p := path + "/" + k
+		bv, existed := before[k]
+		if !existed {
+			ops = append(ops, jsonPatchOp{Op: "add", Path: p, Value: av})
+			continue
+		}
+		bMap, bIsMap := bv.(map[string]interface{})
+		aMap, aIsMap := av.(map[string]interface{})
+		if bIsMap && aIsMap {
+			ops = append(ops, diffToPatch(p, bMap, aMap)...)
+			continue
+		}
+		if !reflect.DeepEqual(bv, av) {
+			ops = append(ops, jsonPatchOp{Op: "replace", Path: p, Value: av})
+		}
+	}
+	for k := range before {
+		if _, ok := after[k]; !ok {
+			ops = append(ops, jsonPatchOp{Op: "remove", Path: path + "/" + k})
+		}
+	}
+	return ops
+}